@@ -0,0 +1,252 @@
+package bee
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	granteeUrl                   = "/grantee"
+	swarmActHeader               = "Swarm-Act"
+	swarmActPublisherHeader      = "Swarm-Act-Publisher"
+	swarmActHistoryAddressHeader = "Swarm-Act-History-Address"
+	swarmActTimestampHeader      = "Swarm-Act-Timestamp"
+)
+
+var (
+	errNoHistoryAddress    = errors.New("grantee update needs the history address of the grantee list")
+	errACTManifestMetadata = errors.New("error document and metadata of single files and manifest metadata are not supported with access control")
+)
+
+// ACT configures access control for the uploads and downloads of a client. Uploads are encrypted
+// for the grantees of the history at HistoryAddress, a new history is started if it is zero.
+// Downloads decrypt references published by Publisher as they were at Timestamp, or now if it is zero.
+type ACT struct {
+	Publisher      *ecdsa.PublicKey
+	HistoryAddress swarm.Address
+	Timestamp      time.Time
+}
+
+type actState struct {
+	mtx sync.Mutex
+	act ACT
+}
+
+type granteesRequest struct {
+	Grantees []string `json:"grantees,omitempty"`
+	Add      []string `json:"add,omitempty"`
+	Revoke   []string `json:"revoke,omitempty"`
+}
+
+type granteesResponse struct {
+	Reference        swarm.Address `json:"ref"`
+	HistoryReference swarm.Address `json:"historyref"`
+}
+
+// WithACT returns a copy of the client that protects all its uploads with access control and
+// decrypts all its downloads. Every upload adds to the history of the previous one, HistoryAddress
+// returns the latest history.
+func (s *Client) WithACT(act ACT) *Client {
+	c := *s
+	c.act = &actState{act: act}
+	return &c
+}
+
+// HistoryAddress returns the ACT history address of the last upload, or the configured one
+func (s *Client) HistoryAddress() swarm.Address {
+	if s.act == nil {
+		return swarm.ZeroAddress
+	}
+	s.act.mtx.Lock()
+	defer s.act.mtx.Unlock()
+	return s.act.act.HistoryAddress
+}
+
+// setACTUploadHeaders marks an upload as access controlled, it continues the history of the client
+func (s *Client) setACTUploadHeaders(req *http.Request) {
+	req.Header.Set(swarmActHeader, "true")
+	if history := s.HistoryAddress(); !history.IsZero() {
+		req.Header.Set(swarmActHistoryAddressHeader, history.String())
+	}
+}
+
+// saveACTHistory keeps the history address the node returned for an access controlled upload. A
+// client without access control keeps none, the upload returns it in its result.
+func (s *Client) saveACTHistory(response *http.Response) {
+	if s.act == nil {
+		return
+	}
+	history, err := swarm.ParseHexAddress(response.Header.Get(swarmActHistoryAddressHeader))
+	if err != nil {
+		return
+	}
+	s.act.mtx.Lock()
+	s.act.act.HistoryAddress = history
+	s.act.mtx.Unlock()
+}
+
+// setACTDownloadHeaders asks the node to decrypt the reference of a download
+func (s *Client) setACTDownloadHeaders(req *http.Request) {
+	if s.act == nil {
+		return
+	}
+	s.act.mtx.Lock()
+	act := s.act.act
+	s.act.mtx.Unlock()
+	if act.Publisher == nil || act.HistoryAddress.IsZero() {
+		return
+	}
+	req.Header.Set(swarmActPublisherHeader, encodePublicKey(act.Publisher))
+	req.Header.Set(swarmActHistoryAddressHeader, act.HistoryAddress.String())
+	if !act.Timestamp.IsZero() {
+		req.Header.Set(swarmActTimestampHeader, strconv.FormatInt(act.Timestamp.Unix(), 10))
+	}
+}
+
+// CreateGrantees uploads a new grantee list. It returns the encrypted reference of the list and
+// the history address, which is the client's ACT history if it has one.
+// Only the batch ID and pinning of opts apply.
+func (s *Client) CreateGrantees(grantees []*ecdsa.PublicKey, opts ...blockstore.UploadOption) (swarm.Address, swarm.Address, error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, err
+	}
+	req, err := s.granteesRequest(http.MethodPost, s.url+granteeUrl, &granteesRequest{Grantees: encodePublicKeys(grantees)}, u, s.HistoryAddress())
+	if err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, err
+	}
+	return s.doGranteesRequest(req)
+}
+
+// UpdateGrantees adds and revokes grantees of the list at reference. Revoking grantees rotates the
+// access key, so only content uploaded afterwards is hidden from them. It returns the new reference
+// of the list and the new history address.
+func (s *Client) UpdateGrantees(reference, historyAddress swarm.Address, add, revoke []*ecdsa.PublicKey, opts ...blockstore.UploadOption) (swarm.Address, swarm.Address, error) {
+	if historyAddress.IsZero() {
+		return swarm.ZeroAddress, swarm.ZeroAddress, errNoHistoryAddress
+	}
+	body := &granteesRequest{Add: encodePublicKeys(add), Revoke: encodePublicKeys(revoke)}
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, err
+	}
+	req, err := s.granteesRequest(http.MethodPatch, s.url+granteeUrl+"/"+reference.String(), body, u, historyAddress)
+	if err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, err
+	}
+	return s.doGranteesRequest(req)
+}
+
+// GetGrantees returns the public keys in the grantee list at reference. Only the publisher can read it.
+func (s *Client) GetGrantees(reference swarm.Address) ([]*ecdsa.PublicKey, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url+granteeUrl+"/"+reference.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+
+	response, err := s.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// skipcq: GO-S2307
+	defer response.Body.Close()
+
+	respData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.New("error getting grantees")
+	}
+	if response.StatusCode != http.StatusOK {
+		var beeErr *beeError
+		err = json.Unmarshal(respData, &beeErr)
+		if err != nil {
+			return nil, errors.New(string(respData))
+		}
+		return nil, errors.New(beeErr.Message)
+	}
+
+	var keys []string
+	if err = json.Unmarshal(respData, &keys); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response")
+	}
+	grantees := make([]*ecdsa.PublicKey, len(keys))
+	for i, key := range keys {
+		if grantees[i], err = pss.ParseRecipient(key); err != nil {
+			return nil, err
+		}
+	}
+	return grantees, nil
+}
+
+func (s *Client) granteesRequest(method, fullUrl string, body *granteesRequest, u uploadSettings, historyAddress swarm.Address) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, fullUrl, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	req.Header.Set(SwarmPostageBatchId, u.batchID)
+	req.Header.Set(contentTypeHeader, "application/json")
+	req.Header.Set(swarmPinHeader, strconv.FormatBool(u.pin))
+	if !historyAddress.IsZero() {
+		req.Header.Set(swarmActHistoryAddressHeader, historyAddress.String())
+	}
+	return req, nil
+}
+
+func (s *Client) doGranteesRequest(req *http.Request) (swarm.Address, swarm.Address, error) {
+	response, err := s.Do(req)
+	if err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, err
+	}
+	// skipcq: GO-S2307
+	defer response.Body.Close()
+
+	respData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, errors.New("error updating grantees")
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		var beeErr *beeError
+		err = json.Unmarshal(respData, &beeErr)
+		if err != nil {
+			return swarm.ZeroAddress, swarm.ZeroAddress, errors.New(string(respData))
+		}
+		return swarm.ZeroAddress, swarm.ZeroAddress, errors.New(beeErr.Message)
+	}
+
+	var resp granteesResponse
+	if err = json.Unmarshal(respData, &resp); err != nil {
+		return swarm.ZeroAddress, swarm.ZeroAddress, fmt.Errorf("error unmarshalling response")
+	}
+	return resp.Reference, resp.HistoryReference, nil
+}
+
+func encodePublicKey(key *ecdsa.PublicKey) string {
+	return hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(key))
+}
+
+func encodePublicKeys(keys []*ecdsa.PublicKey) []string {
+	encoded := make([]string, len(keys))
+	for i, key := range keys {
+		encoded[i] = encodePublicKey(key)
+	}
+	return encoded
+}
@@ -12,8 +12,12 @@ import (
 	"strconv"
 	"time"
 
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/manifest"
 	"github.com/asabya/swarm-blockstore/tar"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
@@ -36,6 +40,13 @@ const (
 	swarmErasureCodingHeader  = "Swarm-Redundancy-Level"
 	swarmTagHeader            = "Swarm-Tag"
 	contentTypeHeader         = "Content-Type"
+	swarmIndexDocumentHeader  = "Swarm-Index-Document"
+	swarmErrorDocumentHeader  = "Swarm-Error-Document"
+
+	swarmRedundancyStrategyHeader     = "Swarm-Redundancy-Strategy"
+	swarmRedundancyFallbackModeHeader = "Swarm-Redundancy-Fallback-Mode"
+	swarmChunkRetrievalTimeoutHeader  = "Swarm-Chunk-Retrieval-Timeout"
+	swarmCacheHeader                  = "Swarm-Cache"
 )
 
 // Client is a bee http client that satisfies blockstore.Client
@@ -44,8 +55,11 @@ type Client struct {
 	client     *http.Client
 	isProxy    bool
 	stamp      string
-	redundancy string
+	redundancy redundancy.Level
 	pin        bool
+	direct     bool
+	act        *actState
+	resolver   Resolver
 }
 
 type bytesPostResponse struct {
@@ -59,8 +73,10 @@ type tagPostRequest struct {
 type tagPostResponse struct {
 	UID       uint32    `json:"uid"`
 	StartedAt time.Time `json:"startedAt"`
-	Total     int64     `json:"total"`
-	Processed int64     `json:"processed"`
+	Split     int64     `json:"split"`
+	Seen      int64     `json:"seen"`
+	Stored    int64     `json:"stored"`
+	Sent      int64     `json:"sent"`
 	Synced    int64     `json:"synced"`
 }
 
@@ -83,12 +99,20 @@ func WithStamp(stamp string) Option {
 	}
 }
 
-func WithRedundancy(level string) Option {
+func WithRedundancy(level redundancy.Level) Option {
 	return func(c *Client) {
 		c.redundancy = level
 	}
 }
 
+// WithDirectUpload makes uploads wait until the node has pushed the data to the network,
+// blockstore.WithDeferred overrides it per call
+func WithDirectUpload(direct bool) Option {
+	return func(c *Client) {
+		c.direct = direct
+	}
+}
+
 // NewBeeClient creates a new client which connects to the Swarm bee node to access the Swarm network.
 func NewBeeClient(apiUrl string, opts ...Option) *Client {
 	c := &Client{
@@ -155,12 +179,139 @@ func (s *Client) checkBee(isProxy bool) (string, error) {
 	return string(data), nil
 }
 
+// uploadSettings are the upload options of a call merged with the defaults of the client
+type uploadSettings struct {
+	batchID string
+	level   redundancy.Level
+	pin     bool
+	encrypt bool
+	tag     uint32
+	direct  bool
+	act     bool
+	bzz     blockstore.BzzOptions
+	result  *blockstore.UploadResult
+}
+
+// uploadSettings applies opts over the defaults of the client
+func (s *Client) uploadSettings(opts []blockstore.UploadOption) (uploadSettings, error) {
+	o := blockstore.NewUploadOptions(opts...)
+	u := uploadSettings{
+		batchID: s.stamp,
+		level:   s.redundancy,
+		pin:     s.pin,
+		tag:     o.Tag,
+		direct:  s.direct,
+		act:     s.act != nil,
+		bzz:     o.Bzz,
+		result:  o.Result,
+	}
+	if o.BatchID != "" {
+		u.batchID = o.BatchID
+	}
+	if o.RedundancyLevel != nil {
+		u.level = *o.RedundancyLevel
+	}
+	if o.Pin != nil {
+		u.pin = *o.Pin
+	}
+	if o.Encrypt != nil {
+		u.encrypt = *o.Encrypt
+	}
+	if o.ACT != nil {
+		u.act = *o.ACT
+	}
+	// the node defers every pinned or tagged upload, a pin or tag of the call wins over the
+	// direct upload default of the client but not over a direct upload the call asks for
+	pinOrTag := (o.Pin != nil && *o.Pin) || o.Tag > 0
+	switch {
+	case o.Deferred != nil && !*o.Deferred && pinOrTag:
+		return uploadSettings{}, blockstore.ErrDirectPinOrTag
+	case o.Deferred != nil:
+		u.direct = !*o.Deferred
+	case pinOrTag:
+		u.direct = false
+	}
+	if u.direct {
+		u.pin = false
+		u.tag = 0
+	}
+	return u, nil
+}
+
+// setUploadHeaders sets the headers of an upload with settings u
+func (s *Client) setUploadHeaders(req *http.Request, u uploadSettings) {
+	req.Header.Set(SwarmPostageBatchId, u.batchID)
+	req.Header.Set(swarmErasureCodingHeader, strconv.Itoa(int(u.level)))
+	req.Header.Set(swarmPinHeader, strconv.FormatBool(u.pin))
+	req.Header.Set(swarmEncryptHeader, strconv.FormatBool(u.encrypt))
+	req.Header.Set(swarmDeferredUploadHeader, strconv.FormatBool(!u.direct))
+	if u.tag > 0 {
+		req.Header.Set(swarmTagHeader, strconv.FormatUint(uint64(u.tag), 10))
+	}
+	if u.act {
+		s.setACTUploadHeaders(req)
+	}
+}
+
+// setResult sets the result of a successful upload with response, pushed is whether the endpoint
+// pushed the upload. The node returns the tag it counted the upload in and the access control history.
+func (u uploadSettings) setResult(pushed bool, response *http.Response) {
+	if u.result == nil {
+		return
+	}
+	*u.result = blockstore.UploadResult{Pushed: pushed, Tag: u.tag}
+	if tag, err := strconv.ParseUint(response.Header.Get(swarmTagHeader), 10, 32); err == nil {
+		u.result.Tag = uint32(tag)
+	}
+	if !u.act {
+		return
+	}
+	if history, err := swarm.ParseHexAddress(response.Header.Get(swarmActHistoryAddressHeader)); err == nil {
+		u.result.HistoryAddress = history
+	}
+}
+
+// uploadError returns the error of a failed upload. The node answers a direct upload whose push
+// failed with a server error.
+func uploadError(direct bool, statusCode int, respData []byte) error {
+	var beeErr *beeError
+	msg := string(respData)
+	if err := json.Unmarshal(respData, &beeErr); err == nil && beeErr != nil {
+		msg = beeErr.Message
+	}
+	if direct && statusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s", blockstore.ErrPushFailed, msg)
+	}
+	return errors.New(msg)
+}
+
+// downloadError returns the error of a failed download or lookup, a missing reference wraps
+// blockstore.ErrNotFound
+func downloadError(statusCode int, respData []byte) error {
+	var beeErr *beeError
+	msg := string(respData)
+	if err := json.Unmarshal(respData, &beeErr); err == nil && beeErr != nil {
+		msg = beeErr.Message
+	}
+	if statusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", blockstore.ErrNotFound, msg)
+	}
+	return errors.New(msg)
+}
+
 func socResource(owner, id, sig string) string {
 	return fmt.Sprintf("/soc/%s/%s?sig=%s", owner, id, sig)
 }
 
-// UploadSOC is used construct and send a Single Owner Chunk to the Swarm bee client.
-func (s *Client) UploadSOC(owner, id, signature, stamp, redundancyLevel string, pin bool, data []byte) (address swarm.Address, err error) {
+// UploadSOC is used construct and send a Single Owner Chunk to the Swarm bee client. The node only
+// defers single owner chunk uploads that are pinned.
+func (s *Client) UploadSOC(owner, id, signature string, data []byte, opts ...blockstore.UploadOption) (address swarm.Address, err error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	// the node pins and defers single owner chunks with the pin header only
+	pushed := !u.pin
 	socResStr := socResource(owner, id, signature)
 	fullUrl := fmt.Sprintf(s.url + socResStr)
 
@@ -169,22 +320,8 @@ func (s *Client) UploadSOC(owner, id, signature, stamp, redundancyLevel string,
 		return swarm.ZeroAddress, err
 	}
 	req.Close = true
-	if stamp == "" {
-		stamp = s.stamp
-	}
-	if redundancyLevel == "" {
-		redundancyLevel = s.redundancy
-	}
-	req.Header.Set(SwarmPostageBatchId, stamp)
 	req.Header.Set(contentTypeHeader, "application/octet-stream")
-	req.Header.Set(swarmDeferredUploadHeader, "true")
-	req.Header.Set(swarmErasureCodingHeader, redundancyLevel)
-	if s.pin {
-		pin = s.pin
-	}
-	if pin {
-		req.Header.Set(swarmPinHeader, "true")
-	}
+	s.setUploadHeaders(req, u)
 	response, err := s.Do(req)
 	if err != nil {
 		return swarm.ZeroAddress, err
@@ -196,12 +333,7 @@ func (s *Client) UploadSOC(owner, id, signature, stamp, redundancyLevel string,
 	}
 
 	if response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(addrData, &beeErr)
-		if err != nil {
-			return swarm.ZeroAddress, errors.New(string(addrData))
-		}
-		return swarm.ZeroAddress, errors.New(beeErr.Message)
+		return swarm.ZeroAddress, uploadError(pushed, response.StatusCode, addrData)
 	}
 
 	var addrResp *chunkAddressResponse
@@ -210,11 +342,30 @@ func (s *Client) UploadSOC(owner, id, signature, stamp, redundancyLevel string,
 		return swarm.ZeroAddress, err
 	}
 
+	s.saveACTHistory(response)
+	u.setResult(pushed, response)
 	return addrResp.Reference, nil
 }
 
-// UploadChunk uploads a chunk to Swarm network.
-func (s *Client) UploadChunk(tag uint32, ch swarm.Chunk, stamp, redundancyLevel string, pin bool) (address swarm.Address, err error) {
+// WriteSOC builds a content addressed chunk from payload, signs it as a single owner chunk with id
+// and uploads it with opts. It returns the SOC address.
+func (s *Client) WriteSOC(ctx context.Context, signer crypto.Signer, id, payload []byte, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	return blockstore.WriteSOC(ctx, s, signer, id, payload, opts...)
+}
+
+// ReadSOC downloads the single owner chunk of owner with id, checks its signature and owner and
+// returns its payload.
+func (s *Client) ReadSOC(ctx context.Context, owner common.Address, id []byte) ([]byte, error) {
+	return blockstore.ReadSOC(ctx, s, owner, id)
+}
+
+// UploadChunk uploads a chunk to Swarm network. The node only defers chunk uploads with a tag.
+func (s *Client) UploadChunk(ch swarm.Chunk, opts ...blockstore.UploadOption) (address swarm.Address, err error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	pushed := u.tag == 0
 	fullUrl := fmt.Sprintf(s.url + chunkUploadDownloadUrl)
 	ctx := context.Background()
 	ctx = redundancy.SetLevelInContext(ctx, redundancy.NONE)
@@ -222,24 +373,9 @@ func (s *Client) UploadChunk(tag uint32, ch swarm.Chunk, stamp, redundancyLevel
 	if err != nil {
 		return swarm.ZeroAddress, err
 	}
-	if stamp == "" {
-		stamp = s.stamp
-	}
-	if redundancyLevel == "" {
-		redundancyLevel = s.redundancy
-	}
 
 	req.Header.Set(contentTypeHeader, "application/octet-stream")
-	req.Header.Set(SwarmPostageBatchId, stamp)
-	req.Header.Set(swarmDeferredUploadHeader, "true")
-	req.Header.Set(swarmErasureCodingHeader, redundancyLevel)
-	req.Header.Set(swarmTagHeader, fmt.Sprintf("%d", tag))
-	if s.pin {
-		pin = s.pin
-	}
-	if pin {
-		req.Header.Set(swarmPinHeader, "true")
-	}
+	s.setUploadHeaders(req, u)
 	req.Close = true
 
 	response, err := s.Do(req)
@@ -254,12 +390,7 @@ func (s *Client) UploadChunk(tag uint32, ch swarm.Chunk, stamp, redundancyLevel
 	}
 
 	if response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(addrData, &beeErr)
-		if err != nil {
-			return swarm.ZeroAddress, errors.New(string(addrData))
-		}
-		return swarm.ZeroAddress, errors.New(beeErr.Message)
+		return swarm.ZeroAddress, uploadError(pushed, response.StatusCode, addrData)
 	}
 
 	var addrResp *chunkAddressResponse
@@ -268,11 +399,13 @@ func (s *Client) UploadChunk(tag uint32, ch swarm.Chunk, stamp, redundancyLevel
 		return swarm.ZeroAddress, err
 	}
 
+	s.saveACTHistory(response)
+	u.setResult(pushed, response)
 	return addrResp.Reference, nil
 }
 
 // DownloadChunk downloads a chunk with given address from the Swarm network
-func (s *Client) DownloadChunk(ctx context.Context, address swarm.Address) (chunk swarm.Chunk, err error) {
+func (s *Client) DownloadChunk(ctx context.Context, address swarm.Address, opts ...blockstore.DownloadOption) (chunk swarm.Chunk, err error) {
 	path := chunkUploadDownloadUrl + "/" + address.String()
 	fullUrl := fmt.Sprintf(s.url + path)
 	req, err := http.NewRequest(http.MethodGet, fullUrl, http.NoBody)
@@ -283,6 +416,11 @@ func (s *Client) DownloadChunk(ctx context.Context, address swarm.Address) (chun
 
 	req = req.WithContext(ctx)
 
+	// the chunk endpoint only reads the cache header
+	if o := blockstore.NewDownloadOptions(opts...); o.Cache != nil {
+		req.Header.Set(swarmCacheHeader, strconv.FormatBool(*o.Cache))
+	}
+	s.setACTDownloadHeaders(req)
 	response, err := s.Do(req)
 	if err != nil {
 		return nil, err
@@ -290,6 +428,9 @@ func (s *Client) DownloadChunk(ctx context.Context, address swarm.Address) (chun
 	// skipcq: GO-S2307
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotFound {
+		return nil, blockstore.ErrNotFound
+	}
 	if response.StatusCode != http.StatusOK {
 		return nil, errors.New("error downloading data")
 	}
@@ -303,29 +444,19 @@ func (s *Client) DownloadChunk(ctx context.Context, address swarm.Address) (chun
 }
 
 // UploadBlob uploads a binary blob of data to Swarm network. It also optionally pins and encrypts the data.
-func (s *Client) UploadBlob(tag uint32, stamp, redundancyLevel string, pin, encrypt bool, data io.Reader) (address swarm.Address, err error) {
+func (s *Client) UploadBlob(data io.Reader, opts ...blockstore.UploadOption) (address swarm.Address, err error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
 	fullUrl := s.url + bytesUploadDownloadUrl
 	req, err := http.NewRequest(http.MethodPost, fullUrl, data)
 	if err != nil {
 		return swarm.ZeroAddress, err
 	}
 	req.Close = true
-	if stamp == "" {
-		stamp = s.stamp
-	}
-	if redundancyLevel == "" {
-		redundancyLevel = s.redundancy
-	}
-	req.Header.Set(swarmPinHeader, fmt.Sprintf("%t", pin))
-	req.Header.Set(swarmEncryptHeader, fmt.Sprintf("%t", encrypt))
 	req.Header.Set(contentTypeHeader, "application/octet-stream")
-	req.Header.Set(swarmErasureCodingHeader, redundancyLevel)
-
-	if tag > 0 {
-		req.Header.Set(swarmTagHeader, fmt.Sprintf("%d", tag))
-	}
-	req.Header.Set(SwarmPostageBatchId, stamp)
-	req.Header.Set(swarmDeferredUploadHeader, "true")
+	s.setUploadHeaders(req, u)
 
 	response, err := s.Do(req)
 	if err != nil {
@@ -339,12 +470,7 @@ func (s *Client) UploadBlob(tag uint32, stamp, redundancyLevel string, pin, encr
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return swarm.ZeroAddress, errors.New(string(respData))
-		}
-		return swarm.ZeroAddress, errors.New(beeErr.Message)
+		return swarm.ZeroAddress, uploadError(u.direct, response.StatusCode, respData)
 	}
 
 	var resp bytesPostResponse
@@ -353,11 +479,13 @@ func (s *Client) UploadBlob(tag uint32, stamp, redundancyLevel string, pin, encr
 		return swarm.ZeroAddress, fmt.Errorf("error unmarshalling response")
 	}
 
+	s.saveACTHistory(response)
+	u.setResult(u.direct, response)
 	return resp.Reference, nil
 }
 
 // DownloadBlob downloads a blob of binary data from the Swarm network.
-func (s *Client) DownloadBlob(address swarm.Address) (io.ReadCloser, int, error) {
+func (s *Client) DownloadBlob(address swarm.Address, opts ...blockstore.DownloadOption) (io.ReadCloser, int, error) {
 
 	fullUrl := s.url + bytesUploadDownloadUrl + "/" + address.String()
 	req, err := http.NewRequest(http.MethodGet, fullUrl, http.NoBody)
@@ -366,6 +494,8 @@ func (s *Client) DownloadBlob(address swarm.Address) (io.ReadCloser, int, error)
 	}
 	req.Close = true
 
+	setDownloadHeaders(req, opts)
+	s.setACTDownloadHeaders(req)
 	response, err := s.Do(req)
 	if err != nil {
 		return nil, http.StatusNotFound, err
@@ -377,19 +507,27 @@ func (s *Client) DownloadBlob(address swarm.Address) (io.ReadCloser, int, error)
 			return nil, response.StatusCode, errors.New("error downloading blob")
 		}
 
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return nil, response.StatusCode, errors.New(string(respData))
-		}
-		return nil, response.StatusCode, errors.New(beeErr.Message)
+		return nil, response.StatusCode, downloadError(response.StatusCode, respData)
 	}
 
 	return response.Body, response.StatusCode, nil
 }
 
-// UploadFileBzz uploads a file through bzz api
-func (s *Client) UploadFileBzz(data []byte, fileName, stamp, redundancyLevel string, pin bool) (address swarm.Address, err error) {
+// UploadFileBzz uploads a file through bzz api. The file is the index document of the manifest,
+// the error document can only be the file itself. If the pin of the uploaded manifest can not be
+// removed after its metadata is added, the pinned changed manifest is returned with the error.
+func (s *Client) UploadFileBzz(data []byte, fileName string, opts ...blockstore.UploadOption) (address swarm.Address, err error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	o := u.bzz
+	if err = o.Validate([]string{fileName}); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if err = checkManifestMetadata(u, o.ErrorDocument); err != nil {
+		return swarm.ZeroAddress, err
+	}
 
 	fullUrl := s.url + bzzUrl + "?name=" + fileName
 	req, err := http.NewRequest(http.MethodPost, fullUrl, bytes.NewBuffer(data))
@@ -397,16 +535,8 @@ func (s *Client) UploadFileBzz(data []byte, fileName, stamp, redundancyLevel str
 		return swarm.ZeroAddress, err
 	}
 	req.Close = true
-	if stamp == "" {
-		stamp = s.stamp
-	}
-	if redundancyLevel == "" {
-		redundancyLevel = s.redundancy
-	}
-	req.Header.Set(swarmPinHeader, fmt.Sprintf("%t", pin))
-	req.Header.Set(SwarmPostageBatchId, stamp)
 	req.Header.Set(contentTypeHeader, "application/json")
-	req.Header.Set(swarmErasureCodingHeader, redundancyLevel)
+	s.setUploadHeaders(req, u)
 
 	response, err := s.Do(req)
 	if err != nil {
@@ -421,12 +551,7 @@ func (s *Client) UploadFileBzz(data []byte, fileName, stamp, redundancyLevel str
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return swarm.ZeroAddress, errors.New(string(respData))
-		}
-		return swarm.ZeroAddress, errors.New(beeErr.Message)
+		return swarm.ZeroAddress, uploadError(u.direct, response.StatusCode, respData)
 	}
 
 	var resp bytesPostResponse
@@ -434,11 +559,30 @@ func (s *Client) UploadFileBzz(data []byte, fileName, stamp, redundancyLevel str
 	if err != nil {
 		return swarm.ZeroAddress, fmt.Errorf("error unmarshalling response")
 	}
-	return resp.Reference, nil
+	s.saveACTHistory(response)
+	// bee sets no error document for single files
+	ref, err := s.addManifestMetadata(resp.Reference, o.ErrorDocument, opts)
+	if ref.IsZero() {
+		return swarm.ZeroAddress, err
+	}
+	u.setResult(u.direct, response)
+	return ref, err
 }
 
-// UploadBzz uploads a tar through bzz api
-func (s *Client) UploadBzz(data *tar.Stream, stamp, redundancyLevel string, pin bool) (address swarm.Address, err error) {
+// UploadBzz uploads a tar through bzz api. The index and error documents have to be files of the tar.
+// Like UploadFileBzz, it returns the changed manifest with the error of a failed unpin.
+func (s *Client) UploadBzz(data *tar.Stream, opts ...blockstore.UploadOption) (address swarm.Address, err error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	o := u.bzz
+	if err = o.Validate(data.Paths()); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if err = checkManifestMetadata(u, ""); err != nil {
+		return swarm.ZeroAddress, err
+	}
 
 	fullUrl := s.url + bzzUrl
 	req, err := http.NewRequest(http.MethodPost, fullUrl, data.Output())
@@ -447,17 +591,15 @@ func (s *Client) UploadBzz(data *tar.Stream, stamp, redundancyLevel string, pin
 	}
 	req.Close = true
 
-	if stamp == "" {
-		stamp = s.stamp
-	}
-	if redundancyLevel == "" {
-		redundancyLevel = s.redundancy
-	}
-	req.Header.Set(swarmPinHeader, fmt.Sprintf("%t", pin))
-	req.Header.Set(SwarmPostageBatchId, stamp)
 	req.Header.Set("Content-Type", "application/x-tar")
 	req.Header.Set("Swarm-Collection", "true")
-	req.Header.Set(swarmErasureCodingHeader, redundancyLevel)
+	s.setUploadHeaders(req, u)
+	if o.IndexDocument != "" {
+		req.Header.Set(swarmIndexDocumentHeader, o.IndexDocument)
+	}
+	if o.ErrorDocument != "" {
+		req.Header.Set(swarmErrorDocumentHeader, o.ErrorDocument)
+	}
 
 	response, err := s.Do(req)
 	if err != nil {
@@ -471,12 +613,7 @@ func (s *Client) UploadBzz(data *tar.Stream, stamp, redundancyLevel string, pin
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return swarm.ZeroAddress, errors.New(string(respData))
-		}
-		return swarm.ZeroAddress, errors.New(beeErr.Message)
+		return swarm.ZeroAddress, uploadError(u.direct, response.StatusCode, respData)
 	}
 
 	var resp bytesPostResponse
@@ -484,20 +621,75 @@ func (s *Client) UploadBzz(data *tar.Stream, stamp, redundancyLevel string, pin
 	if err != nil {
 		return swarm.ZeroAddress, fmt.Errorf("error unmarshalling response")
 	}
-	return resp.Reference, nil
+	s.saveACTHistory(response)
+	ref, err := s.addManifestMetadata(resp.Reference, "", opts)
+	if ref.IsZero() {
+		return swarm.ZeroAddress, err
+	}
+	u.setResult(u.direct, response)
+	return ref, err
+}
+
+// checkManifestMetadata fails if the manifest of an upload has to be changed after it is uploaded,
+// but its reference is encrypted with access control
+func checkManifestMetadata(u uploadSettings, errorDocument string) error {
+	if u.act && (errorDocument != "" || len(u.bzz.Metadata) > 0) {
+		return errACTManifestMetadata
+	}
+	return nil
+}
+
+// addManifestMetadata adds the metadata that bee can not set on upload to the root of the manifest at
+// ref and returns the reference of the changed manifest. The manifest nodes are uploaded with opts.
+// A pinned upload moves its pin from ref to the changed manifest. If ref can not be unpinned, the
+// changed manifest is pinned and returned with the error.
+func (s *Client) addManifestMetadata(ref swarm.Address, errorDocument string, opts []blockstore.UploadOption) (swarm.Address, error) {
+	metadata := blockstore.NewUploadOptions(opts...).Bzz.Metadata
+	if errorDocument == "" && len(metadata) == 0 {
+		return ref, nil
+	}
+	ls, err := manifest.NewClientLoadSaver(s, opts...)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	b, err := manifest.LoadBuilder(ls, ref)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	b.SetErrorDocument(errorDocument)
+	b.SetRootMetadata(metadata)
+	changed, err := b.Save(context.Background())
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if u, err := s.uploadSettings(opts); err != nil || !u.pin {
+		return changed, err
+	}
+	if err = s.pinReference(changed); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("pinning manifest %s, %s stays pinned: %w", changed, ref, err)
+	}
+	if err = s.DeleteReference(ref); err != nil {
+		return changed, fmt.Errorf("unpinning manifest %s, %s is pinned: %w", ref, changed, err)
+	}
+	return changed, nil
 }
 
 // DownloadBzz downloads bzz data from the Swarm network.
-func (s *Client) DownloadBzz(address swarm.Address) ([]byte, int, error) {
+func (s *Client) DownloadBzz(address swarm.Address, opts ...blockstore.DownloadOption) ([]byte, int, error) {
+	return s.downloadBzz(address.String(), opts)
+}
 
-	addrString := address.String()
-	fullUrl := s.url + bzzUrl + "/" + addrString
+// downloadBzz downloads the bzz data of target, a reference or a name the node resolves
+func (s *Client) downloadBzz(target string, opts []blockstore.DownloadOption) ([]byte, int, error) {
+	fullUrl := s.url + bzzUrl + "/" + target
 	req, err := http.NewRequest(http.MethodGet, fullUrl, http.NoBody)
 	if err != nil {
 		return nil, http.StatusNotFound, err
 	}
 	req.Close = true
 
+	setDownloadHeaders(req, opts)
+	s.setACTDownloadHeaders(req)
 	response, err := s.Do(req)
 	if err != nil {
 		return nil, http.StatusNotFound, err
@@ -511,26 +703,27 @@ func (s *Client) DownloadBzz(address swarm.Address) ([]byte, int, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return nil, response.StatusCode, errors.New(string(respData))
-		}
-		return nil, response.StatusCode, errors.New(beeErr.Message)
+		return nil, response.StatusCode, downloadError(response.StatusCode, respData)
 	}
 	return respData, response.StatusCode, nil
 }
 
 // DownloadFileBzz downloads file at bzz collection from the Swarm network.
-func (s *Client) DownloadFileBzz(address swarm.Address, filename string) (io.ReadCloser, uint64, error) {
+func (s *Client) DownloadFileBzz(address swarm.Address, filename string, opts ...blockstore.DownloadOption) (io.ReadCloser, uint64, error) {
+	return s.downloadFileBzz(address.String(), filename, opts)
+}
 
-	fullUrl := s.url + filepath.ToSlash(filepath.Join(bzzUrl, address.String(), filename))
+// downloadFileBzz downloads filename from the collection at target, a reference or a name the node resolves
+func (s *Client) downloadFileBzz(target, filename string, opts []blockstore.DownloadOption) (io.ReadCloser, uint64, error) {
+	fullUrl := s.url + filepath.ToSlash(filepath.Join(bzzUrl, target, filename))
 	req, err := http.NewRequest(http.MethodGet, fullUrl, http.NoBody)
 	if err != nil {
 		return nil, 0, err
 	}
 	req.Close = true
 
+	setDownloadHeaders(req, opts)
+	s.setACTDownloadHeaders(req)
 	response, err := s.Do(req)
 	if err != nil {
 		return nil, 0, err
@@ -542,12 +735,7 @@ func (s *Client) DownloadFileBzz(address swarm.Address, filename string) (io.Rea
 			return nil, 0, errors.New("error downloading bzz")
 		}
 
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return nil, 0, errors.New(string(respData))
-		}
-		return nil, 0, errors.New(beeErr.Message)
+		return nil, 0, downloadError(response.StatusCode, respData)
 	}
 
 	contentLength, err := strconv.ParseUint(response.Header.Get("Content-Length"), 10, 64)
@@ -558,6 +746,92 @@ func (s *Client) DownloadFileBzz(address swarm.Address, filename string) (io.Rea
 	return response.Body, contentLength, nil
 }
 
+// setDownloadHeaders sets the retrieval headers of the download options that are set
+func setDownloadHeaders(req *http.Request, opts []blockstore.DownloadOption) {
+	o := blockstore.NewDownloadOptions(opts...)
+	if o.RedundancyStrategy != nil {
+		req.Header.Set(swarmRedundancyStrategyHeader, strconv.Itoa(int(*o.RedundancyStrategy)))
+	}
+	if o.Fallback != nil {
+		req.Header.Set(swarmRedundancyFallbackModeHeader, strconv.FormatBool(*o.Fallback))
+	}
+	if o.ChunkRetrievalTimeout > 0 {
+		req.Header.Set(swarmChunkRetrievalTimeoutHeader, o.ChunkRetrievalTimeout.String())
+	}
+	if o.Cache != nil {
+		req.Header.Set(swarmCacheHeader, strconv.FormatBool(*o.Cache))
+	}
+}
+
+// ListManifest returns the entries of the Mantaray manifest at address, walking its nodes with DownloadChunk
+func (s *Client) ListManifest(ctx context.Context, address swarm.Address) ([]*manifest.Entry, error) {
+	return manifest.ListManifest(ctx, s, address)
+}
+
+// ResolvePath returns the reference and metadata served for path in the manifest at address
+func (s *Client) ResolvePath(ctx context.Context, address swarm.Address, path string) (*manifest.Entry, error) {
+	return manifest.ResolvePath(ctx, s, address, path)
+}
+
+// DiffManifests compares the Mantaray manifests at from and to, skipping the subtrees they share
+func (s *Client) DiffManifests(ctx context.Context, from, to swarm.Address) (*manifest.Diff, error) {
+	return manifest.DiffManifests(ctx, s, from, to)
+}
+
+// IsPinned reports whether the node pins address
+func (s *Client) IsPinned(address swarm.Address) (bool, error) {
+	fullUrl := s.url + pinsUrl + address.String()
+	req, err := http.NewRequest(http.MethodGet, fullUrl, http.NoBody)
+	if err != nil {
+		return false, err
+	}
+	req.Close = true
+
+	response, err := s.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	respData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return false, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to check pin : %s", respData)
+	}
+}
+
+// pinReference pins address and the chunks it references, which the node already stores
+func (s *Client) pinReference(address swarm.Address) error {
+	fullUrl := s.url + pinsUrl + address.String()
+	req, err := http.NewRequest(http.MethodPost, fullUrl, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Close = true
+
+	response, err := s.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	respData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to pin reference : %s", respData)
+	}
+	return nil
+}
+
 // DeleteReference unpins a reference so that it will be garbage collected by the Swarm network.
 func (s *Client) DeleteReference(address swarm.Address) error {
 
@@ -641,7 +915,12 @@ func (s *Client) CreateTag(address swarm.Address) (uint32, error) {
 	return resp.UID, nil
 }
 
-func (s *Client) CreateFeedManifest(owner, topic, stamp string, pin bool) (swarm.Address, error) {
+// CreateFeedManifest uploads the manifest of the feed of owner and topic
+func (s *Client) CreateFeedManifest(owner, topic string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := s.uploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
 
 	fullUrl := s.url + feedsUrl + owner + "/" + topic
 	req, err := http.NewRequest(http.MethodPost, fullUrl, nil)
@@ -649,16 +928,7 @@ func (s *Client) CreateFeedManifest(owner, topic, stamp string, pin bool) (swarm
 		return swarm.ZeroAddress, err
 	}
 	req.Close = true
-	if stamp == "" {
-		stamp = s.stamp
-	}
-	req.Header.Set(SwarmPostageBatchId, stamp)
-	if s.pin {
-		pin = s.pin
-	}
-	if pin {
-		req.Header.Set(swarmPinHeader, "true")
-	}
+	s.setUploadHeaders(req, u)
 	response, err := s.Do(req)
 	if err != nil {
 		return swarm.ZeroAddress, err
@@ -685,6 +955,8 @@ func (s *Client) CreateFeedManifest(owner, topic, stamp string, pin bool) (swarm
 		return swarm.ZeroAddress, fmt.Errorf("error unmarshalling response")
 	}
 
+	s.saveACTHistory(response)
+	u.setResult(u.direct, response)
 	return resp.Reference, nil
 }
 
@@ -710,12 +982,7 @@ func (s *Client) GetLatestFeedManifest(owner, topic string) (swarm.Address, stri
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return swarm.ZeroAddress, "", "", errors.New(string(respData))
-		}
-		return swarm.ZeroAddress, "", "", errors.New(beeErr.Message)
+		return swarm.ZeroAddress, "", "", downloadError(response.StatusCode, respData)
 	}
 
 	var resp bytesPostResponse
@@ -755,12 +1022,7 @@ func (s *Client) GetTag(tag uint32) (int64, int64, int64, error) {
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
-		var beeErr *beeError
-		err = json.Unmarshal(respData, &beeErr)
-		if err != nil {
-			return 0, 0, 0, errors.New(string(respData))
-		}
-		return 0, 0, 0, errors.New(beeErr.Message)
+		return 0, 0, 0, downloadError(response.StatusCode, respData)
 	}
 
 	var resp tagPostResponse
@@ -769,7 +1031,8 @@ func (s *Client) GetTag(tag uint32) (int64, int64, int64, error) {
 		return 0, 0, 0, fmt.Errorf("error unmarshalling response")
 	}
 
-	return resp.Total, resp.Processed, resp.Synced, nil
+	// chunks the node has seen before are not stored or sent again
+	return resp.Split, resp.Seen + resp.Stored, resp.Synced, nil
 }
 
 // createHTTPClient for connection re-use
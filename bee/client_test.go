@@ -0,0 +1,862 @@
+package bee_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/bee"
+	"github.com/asabya/swarm-blockstore/bee/mock"
+	"github.com/asabya/swarm-blockstore/blockstoretest"
+	"github.com/asabya/swarm-blockstore/tar"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy/getter"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/v2/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	resolvermock "github.com/ethersphere/bee/v2/pkg/resolver/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/gorilla/websocket"
+)
+
+func newTestClient(t *testing.T, o mock.TestServerOptions) *bee.Client {
+	t.Helper()
+	return mock.NewTestClient(t, o)
+}
+
+func TestConformance(t *testing.T) {
+	// the mock storer of bee never updates a tag after it is created
+	blockstoretest.TestClient(t, func(t *testing.T) blockstore.Client {
+		return newTestClient(t, mock.TestServerOptions{})
+	}, blockstoretest.WithoutTagCounts())
+}
+
+func TestGetTag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tags/7" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"uid":7,"split":10,"seen":3,"stored":5,"sent":4,"synced":2}`))
+	}))
+	defer srv.Close()
+	client := bee.NewBeeClient(srv.URL)
+
+	total, processed, synced, err := client.GetTag(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 10 || processed != 8 || synced != 2 {
+		t.Fatalf("expected 10 total, 8 processed, 2 synced, got %d, %d, %d", total, processed, synced)
+	}
+	if _, _, _, err = client.GetTag(8); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func newTestSigner(t *testing.T) (crypto.Signer, common.Address) {
+	t.Helper()
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(pk)
+	addr, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, addr
+}
+
+func TestSOC(t *testing.T) {
+	client := newTestClient(t, mock.TestServerOptions{})
+	signer, owner := newTestSigner(t)
+	ctx := context.Background()
+	id := bytes.Repeat([]byte{1}, 32)
+	payload := []byte("single owner chunk")
+
+	_, err := client.WriteSOC(ctx, signer, id[:31], payload)
+	if err == nil {
+		t.Fatal("expected error for short id")
+	}
+	addr, err := client.WriteSOC(ctx, signer, id, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.IsZero() {
+		t.Fatal("expected soc address")
+	}
+
+	got, err := client.ReadSOC(ctx, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("expected payload %q, got %q", payload, got)
+	}
+
+	_, other := newTestSigner(t)
+	_, err = client.ReadSOC(ctx, other, id)
+	if !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPSS(t *testing.T) {
+	nodeKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// deliver every sent message back to the node itself
+	ps := pss.New(nodeKey, log.Noop)
+	ps.SetPushSyncer(pushsyncmock.New(func(ctx context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		ps.TryUnwrap(ch)
+		return &pushsync.Receipt{}, nil
+	}))
+	client := newTestClient(t, mock.TestServerOptions{Pss: ps})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err = client.SendPSS(ctx, "topic", nil, nil, []byte("no targets")); err == nil {
+		t.Fatal("expected error without targets")
+	}
+
+	messages, err := client.SubscribePSS(ctx, "topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, recipient := range []*ecdsa.PublicKey{nil, &nodeKey.PublicKey} {
+		// the node registers the subscription asynchronously, so resend until it arrives
+		received := false
+		for i := 0; i < 50 && !received; i++ {
+			if err = client.SendPSS(ctx, "topic", []string{"ab"}, recipient, []byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case msg := <-messages:
+				if string(msg) != "hello" {
+					t.Fatalf("expected message %q, got %q", "hello", msg)
+				}
+				received = true
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		if !received {
+			t.Fatal("pss message not received")
+		}
+	}
+
+	cancel()
+	for range messages {
+	}
+}
+
+func TestGSOC(t *testing.T) {
+	client := newTestClient(t, mock.TestServerOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	id := bytes.Repeat([]byte{2}, 32)
+	target := swarm.RandAddress(t)
+
+	if _, err := bee.MineGSOCKey(ctx, target, id, 25); err == nil {
+		t.Fatal("expected error for too high proximity")
+	}
+	key, err := bee.MineGSOCKey(ctx, target, id, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := bee.GSOCAddress(owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if po := swarm.Proximity(addr.Bytes(), target.Bytes()); po < 8 {
+		t.Fatalf("expected proximity of at least 8, got %d", po)
+	}
+
+	uploaded, err := client.SendGSOC(ctx, signer, id, []byte("gsoc update"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !uploaded.Equal(addr) {
+		t.Fatalf("expected gsoc address %s, got %s", addr, uploaded)
+	}
+	payload, err := client.ReadSOC(ctx, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != "gsoc update" {
+		t.Fatalf("unexpected gsoc payload %q", payload)
+	}
+
+	// bee 2.2 has no gsoc endpoint, serve one that drops the connection after every message
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gsoc/subscribe/"+addr.String() {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := connections.Add(1)
+		_ = conn.WriteMessage(websocket.BinaryMessage, []byte(strings.Repeat("x", int(n))))
+	}))
+	defer server.Close()
+
+	messages, err := bee.NewBeeClient(server.URL).SubscribeGSOC(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		select {
+		case msg := <-messages:
+			if !msg.Address.Equal(addr) || string(msg.Payload) != strings.Repeat("x", i) {
+				t.Fatalf("unexpected message %s %q", msg.Address, msg.Payload)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for message %d", i)
+		}
+	}
+	cancel()
+	for range messages {
+	}
+}
+
+func TestACT(t *testing.T) {
+	nodeKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, mock.TestServerOptions{
+		PublicKey:     nodeKey.PublicKey,
+		AccessControl: accesscontrol.NewController(accesscontrol.NewLogic(accesscontrol.NewDefaultSession(nodeKey))),
+	})
+	data := []byte("access controlled")
+
+	publisher := client.WithACT(bee.ACT{})
+	ref, err := publisher.UploadBlob(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := publisher.HistoryAddress()
+	if history.IsZero() {
+		t.Fatal("expected act history address")
+	}
+	if !client.HistoryAddress().IsZero() {
+		t.Fatal("act must not leak into the original client")
+	}
+	if _, _, err = client.DownloadBlob(ref); err == nil {
+		t.Fatal("expected download without act to fail")
+	}
+
+	fileRef, err := publisher.UploadFileBzz(data, "act.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if publisher.HistoryAddress().IsZero() {
+		t.Fatal("expected act history address after second upload")
+	}
+
+	reader := client.WithACT(bee.ACT{Publisher: &nodeKey.PublicKey, HistoryAddress: publisher.HistoryAddress()})
+	r, _, err := reader.DownloadBlob(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+	r, _, err = reader.DownloadFileBzz(fileRef, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+
+	// an upload with access control on a client without it returns its new history
+	var result blockstore.UploadResult
+	ref, err = client.UploadBlob(bytes.NewReader(data), blockstore.WithACT(true), blockstore.WithResult(&result))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.HistoryAddress.IsZero() {
+		t.Fatal("expected act history address in the result")
+	}
+	if !client.HistoryAddress().IsZero() {
+		t.Fatal("act must not leak into the original client")
+	}
+	r, _, err = client.WithACT(bee.ACT{Publisher: &nodeKey.PublicKey, HistoryAddress: result.HistoryAddress}).DownloadBlob(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+
+	first, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listRef, listHistory, err := client.CreateGrantees([]*ecdsa.PublicKey{&first.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grantees, err := client.GetGrantees(listRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grantees) != 1 || !grantees[0].Equal(&first.PublicKey) {
+		t.Fatalf("unexpected grantees %v", grantees)
+	}
+
+	if _, _, err = client.UpdateGrantees(listRef, swarm.ZeroAddress, nil, nil); err == nil {
+		t.Fatal("expected error without history address")
+	}
+	// act history entries are keyed by unix second, the node rejects a second entry in the same second
+	time.Sleep(1100 * time.Millisecond)
+	listRef, _, err = client.UpdateGrantees(listRef, listHistory, []*ecdsa.PublicKey{&second.PublicKey}, []*ecdsa.PublicKey{&first.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grantees, err = client.GetGrantees(listRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grantees) != 1 || !grantees[0].Equal(&second.PublicKey) {
+		t.Fatalf("unexpected grantees after update %v", grantees)
+	}
+}
+
+func TestUploadBzzOptions(t *testing.T) {
+	st := mockstorer.New()
+	client := newTestClient(t, mock.TestServerOptions{Storer: st})
+
+	newStream := func() *tar.Stream {
+		s := tar.NewStream()
+		for p, data := range map[string]string{
+			"index.html":       "home",
+			"404.html":         "not found",
+			"about/index.html": "about",
+		} {
+			if err := s.WriteItem(tar.CollectionItem{Path: p, Size: int64(len(data)), File: io.NopCloser(strings.NewReader(data))}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.End(); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	_, err := client.UploadBzz(newStream(), blockstore.WithIndexDocument("home.html"))
+	if !errors.Is(err, blockstore.ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound, got %v", err)
+	}
+
+	ref, err := client.UploadBzz(newStream(),
+		blockstore.WithIndexDocument("index.html"),
+		blockstore.WithErrorDocument("404.html"),
+		blockstore.WithMetadata(map[string]string{"version": "1.2.0"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, expected := range map[string]string{"": "home", "about/": "about", "missing.html": "not found"} {
+		r, _, err := client.DownloadFileBzz(ref, p)
+		if err != nil {
+			t.Fatalf("%q: %v", p, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("%q: expected %q, got %q", p, expected, data)
+		}
+	}
+	metadata := rootMetadata(t, client, ref)
+	if metadata["version"] != "1.2.0" || metadata[manifest.WebsiteErrorDocumentPathKey] != "404.html" {
+		t.Fatalf("unexpected root metadata %v", metadata)
+	}
+	// the pin moves to the changed manifest, so unpinning it leaves no pin behind
+	if pins, err := st.Pins(); err != nil || len(pins) != 1 || !pins[0].Equal(ref) {
+		t.Fatalf("expected only %s to be pinned, got %v, %v", ref, pins, err)
+	}
+	if err = client.DeleteReference(ref); err != nil {
+		t.Fatal(err)
+	}
+	if pins, err := st.Pins(); err != nil || len(pins) != 0 {
+		t.Fatalf("expected no pins, got %v, %v", pins, err)
+	}
+
+	_, err = client.UploadFileBzz([]byte("{}"), "data.json", blockstore.WithErrorDocument("404.html"))
+	if !errors.Is(err, blockstore.ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound, got %v", err)
+	}
+	ref, err = client.UploadFileBzz([]byte("{}"), "data.json", blockstore.WithErrorDocument("data.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := client.DownloadFileBzz(ref, "missing.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "{}" {
+		t.Fatalf("expected the error document, got %q, %v", data, err)
+	}
+	if metadata = rootMetadata(t, client, ref); metadata[manifest.WebsiteIndexDocumentSuffixKey] != "data.json" {
+		t.Fatalf("unexpected root metadata %v", metadata)
+	}
+
+	if _, err = client.WithACT(bee.ACT{}).UploadBzz(newStream(), blockstore.WithMetadata(map[string]string{"a": "b"})); err == nil {
+		t.Fatal("expected manifest metadata to be rejected with access control")
+	}
+}
+
+func TestUploadBzzUnpinFailure(t *testing.T) {
+	st := mockstorer.New()
+	target, err := url.Parse(mock.NewTestServer(t, mock.TestServerOptions{Storer: st}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/pins/") {
+			http.Error(w, `{"code":500,"message":"unpin failed"}`, http.StatusInternalServerError)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	client := bee.NewBeeClient(server.URL, bee.WithStamp(mock.BatchOkStr), bee.WithRedundancy(redundancy.NONE), bee.WithPinning(true))
+
+	// the changed manifest is pinned and returned with the error
+	ref, err := client.UploadFileBzz([]byte("{}"), "data.json", blockstore.WithMetadata(map[string]string{"a": "b"}))
+	if err == nil || ref.IsZero() {
+		t.Fatalf("expected a reference and an error, got %s, %v", ref, err)
+	}
+	if pinned, err := client.IsPinned(ref); err != nil || !pinned {
+		t.Fatalf("expected %s to be pinned, got %v, %v", ref, pinned, err)
+	}
+	if metadata := rootMetadata(t, client, ref); metadata["a"] != "b" {
+		t.Fatalf("unexpected root metadata %v", metadata)
+	}
+}
+
+func TestUploadBzzErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":500,"message":"push failed"}`, http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	client := bee.NewBeeClient(server.URL, bee.WithStamp(mock.BatchOkStr), bee.WithDirectUpload(true))
+	stream := func() *tar.Stream {
+		s := tar.NewStream()
+		if err := s.WriteItem(tar.CollectionItem{Path: "index.html", Size: 4, File: io.NopCloser(strings.NewReader("home"))}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.End(); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	if _, err := client.UploadFileBzz([]byte("{}"), "data.json"); !errors.Is(err, blockstore.ErrPushFailed) {
+		t.Fatalf("expected %v, got %v", blockstore.ErrPushFailed, err)
+	}
+	if _, err := client.UploadBzz(stream()); !errors.Is(err, blockstore.ErrPushFailed) {
+		t.Fatalf("expected %v, got %v", blockstore.ErrPushFailed, err)
+	}
+	// a deferred upload is not pushed, so it can not fail to push
+	if _, err := client.UploadBzz(stream(), blockstore.WithDeferred(true)); err == nil || errors.Is(err, blockstore.ErrPushFailed) {
+		t.Fatalf("expected a plain error, got %v", err)
+	}
+	if _, err := client.UploadFileBzz([]byte("{}"), "data.json", blockstore.WithPin(true), blockstore.WithDeferred(false)); !errors.Is(err, blockstore.ErrDirectPinOrTag) {
+		t.Fatalf("expected %v, got %v", blockstore.ErrDirectPinOrTag, err)
+	}
+}
+
+func rootMetadata(t *testing.T, client *bee.Client, ref swarm.Address) map[string]string {
+	t.Helper()
+	m, err := manifest.NewMantarayManifestReference(ref, loadsave.NewReadonly(storage.GetterFunc(func(ctx context.Context, address swarm.Address) (swarm.Chunk, error) {
+		return client.DownloadChunk(ctx, address)
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := m.Lookup(context.Background(), manifest.RootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e.Metadata()
+}
+
+type testResolver map[string]swarm.Address
+
+func (r testResolver) Resolve(_ context.Context, name string) (swarm.Address, error) {
+	if addr, ok := r[name]; ok {
+		return addr, nil
+	}
+	return swarm.ZeroAddress, blockstore.ErrNotFound
+}
+
+func TestDownloadBzzName(t *testing.T) {
+	var ref swarm.Address
+	beeUrl := mock.NewTestServer(t, mock.TestServerOptions{
+		Resolver: resolvermock.NewResolver(resolvermock.WithResolveFunc(func(name string) (swarm.Address, error) {
+			if name == "site.eth" {
+				return ref, nil
+			}
+			return swarm.ZeroAddress, resolver.ErrNotFound
+		})),
+	})
+	opts := []bee.Option{bee.WithStamp(mock.BatchOkStr), bee.WithRedundancy(redundancy.NONE), bee.WithPinning(true)}
+	client := bee.NewBeeClient(beeUrl, opts...)
+	ref, err := client.UploadFileBzz([]byte("hello"), "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// names are resolved by the node
+	data, _, err := client.DownloadBzzName("site.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("unexpected data %q", data)
+	}
+	if _, _, err = client.DownloadBzzName("other.eth"); err == nil {
+		t.Fatal("expected unknown name to fail")
+	}
+
+	// names are resolved by the client
+	local := bee.NewBeeClient(beeUrl, append(opts, bee.WithResolver(testResolver{"other.eth": ref}))...)
+	r, _, err := local.DownloadFileBzzName("other.eth", "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, err = io.ReadAll(r); err != nil || string(data) != "hello" {
+		t.Fatalf("unexpected data %q, %v", data, err)
+	}
+	if _, _, err = local.DownloadBzzName("site.eth"); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// newRecordingClient returns a pinning client whose requests pass a proxy that keeps the headers
+// of the last request to the node
+func newRecordingClient(t *testing.T) (*bee.Client, *atomic.Value) {
+	t.Helper()
+	beeUrl := mock.NewTestServer(t, mock.TestServerOptions{})
+	target, err := url.Parse(beeUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := &atomic.Value{}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers.Store(r.Header.Clone())
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return bee.NewBeeClient(server.URL, bee.WithStamp(mock.BatchOkStr), bee.WithRedundancy(redundancy.NONE), bee.WithPinning(true)), headers
+}
+
+func TestDownloadOptions(t *testing.T) {
+	client, headers := newRecordingClient(t)
+
+	ref, err := client.UploadFileBzz([]byte("hello"), "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := client.DownloadFileBzz(ref, "hello.txt",
+		blockstore.WithRedundancyStrategy(getter.RACE),
+		blockstore.WithFallback(false),
+		blockstore.WithChunkRetrievalTimeout(2*time.Second),
+		blockstore.WithCache(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("unexpected data %q, %v", data, err)
+	}
+	h := headers.Load().(http.Header)
+	for name, expected := range map[string]string{
+		"Swarm-Redundancy-Strategy":      "3",
+		"Swarm-Redundancy-Fallback-Mode": "false",
+		"Swarm-Chunk-Retrieval-Timeout":  "2s",
+		"Swarm-Cache":                    "false",
+	} {
+		if got := h.Get(name); got != expected {
+			t.Fatalf("expected %s header %q, got %q", name, expected, got)
+		}
+	}
+
+	// unset options are left to the node
+	if _, _, err = client.DownloadBzz(ref); err != nil {
+		t.Fatal(err)
+	}
+	h = headers.Load().(http.Header)
+	if h.Get("Swarm-Redundancy-Strategy") != "" || h.Get("Swarm-Cache") != "" {
+		t.Fatalf("unexpected download headers %v", h)
+	}
+
+	// chunk downloads send the cache header only
+	_, err = client.DownloadChunk(context.Background(), ref,
+		blockstore.WithRedundancyStrategy(getter.RACE),
+		blockstore.WithFallback(false),
+		blockstore.WithChunkRetrievalTimeout(2*time.Second),
+		blockstore.WithCache(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h = headers.Load().(http.Header)
+	if h.Get("Swarm-Cache") != "true" {
+		t.Fatalf("expected chunk download to set Swarm-Cache, got %v", h)
+	}
+	for _, name := range []string{"Swarm-Redundancy-Strategy", "Swarm-Redundancy-Fallback-Mode", "Swarm-Chunk-Retrieval-Timeout"} {
+		if got := h.Get(name); got != "" {
+			t.Fatalf("expected no %s header on chunk downloads, got %q", name, got)
+		}
+	}
+}
+
+func TestDirectUpload(t *testing.T) {
+	st := mockstorer.New()
+	beeUrl := mock.NewTestServer(t, mock.TestServerOptions{Storer: st, DirectUpload: true})
+	client := bee.NewBeeClient(beeUrl, bee.WithStamp(mock.BatchOkStr), bee.WithRedundancy(redundancy.NONE), bee.WithDirectUpload(true))
+	ctx := context.Background()
+	expectStored := func(addr swarm.Address, expected bool) {
+		t.Helper()
+		has, err := st.ChunkStore().Has(ctx, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if has != expected {
+			t.Fatalf("expected stored %v for %s, got %v", expected, addr, has)
+		}
+	}
+	expectResult := func(result blockstore.UploadResult, pushed bool) {
+		t.Helper()
+		if result.Pushed != pushed {
+			t.Fatalf("expected pushed %v, got %v", pushed, result.Pushed)
+		}
+	}
+
+	// the node defers chunk uploads with a tag only, whatever the call asks for
+	tag, err := client.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name   string
+		opts   []blockstore.UploadOption
+		pushed bool
+	}{
+		{"direct", nil, true},
+		{"deferred", []blockstore.UploadOption{blockstore.WithDeferred(true)}, true},
+		{"tagged", []blockstore.UploadOption{blockstore.WithTag(tag)}, false},
+	} {
+		ch, err := cac.New([]byte(tc.name + " chunk"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result blockstore.UploadResult
+		addr, err := client.UploadChunk(ch, append(tc.opts, blockstore.WithResult(&result))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !addr.Equal(ch.Address()) {
+			t.Fatalf("expected %s, got %s", ch.Address(), addr)
+		}
+		expectStored(addr, !tc.pushed)
+		expectResult(result, tc.pushed)
+	}
+
+	// and single owner chunk uploads that are pinned only
+	signer, _ := newTestSigner(t)
+	for i, tc := range []struct {
+		name   string
+		opts   []blockstore.UploadOption
+		pushed bool
+	}{
+		{"direct", nil, true},
+		{"deferred", []blockstore.UploadOption{blockstore.WithDeferred(true)}, true},
+		{"pinned", []blockstore.UploadOption{blockstore.WithPin(true)}, false},
+	} {
+		var result blockstore.UploadResult
+		addr, err := client.WriteSOC(ctx, signer, bytes.Repeat([]byte{byte(i)}, 32), []byte(tc.name+" soc"), append(tc.opts, blockstore.WithResult(&result))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectStored(addr, !tc.pushed)
+		expectResult(result, tc.pushed)
+	}
+
+	var result blockstore.UploadResult
+	addr, err := client.UploadBlob(strings.NewReader("direct blob"), blockstore.WithResult(&result))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStored(addr, false)
+	expectResult(result, true)
+
+	// the call overrides the client default
+	addr, err = client.UploadBlob(strings.NewReader("deferred blob"), blockstore.WithDeferred(true), blockstore.WithResult(&result))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStored(addr, true)
+	expectResult(result, false)
+
+	// a pin of the call makes the upload deferred
+	addr, err = client.UploadBlob(strings.NewReader("pinned blob"), blockstore.WithPin(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStored(addr, true)
+	if pinned, err := client.IsPinned(addr); err != nil || !pinned {
+		t.Fatalf("expected pinned blob to be pinned, got %v, %v", pinned, err)
+	}
+
+	// and so does a tag, which the result returns
+	addr, err = client.UploadBlob(strings.NewReader("tagged blob"), blockstore.WithTag(tag), blockstore.WithResult(&result))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStored(addr, true)
+	expectResult(result, false)
+	if result.Tag != tag {
+		t.Fatalf("expected tag %d, got %d", tag, result.Tag)
+	}
+
+	// unless the call asks for a direct upload too
+	_, err = client.UploadBlob(strings.NewReader("conflicting blob"), blockstore.WithPin(true), blockstore.WithDeferred(false))
+	if !errors.Is(err, blockstore.ErrDirectPinOrTag) {
+		t.Fatalf("expected %v, got %v", blockstore.ErrDirectPinOrTag, err)
+	}
+	_, err = client.UploadBlob(strings.NewReader("conflicting blob"), blockstore.WithTag(tag), blockstore.WithDeferred(false))
+	if !errors.Is(err, blockstore.ErrDirectPinOrTag) {
+		t.Fatalf("expected %v, got %v", blockstore.ErrDirectPinOrTag, err)
+	}
+}
+
+func TestUploadOptions(t *testing.T) {
+	client, headers := newRecordingClient(t)
+	expectHeaders := func(expected map[string]string) {
+		t.Helper()
+		h := headers.Load().(http.Header)
+		for name, value := range expected {
+			if got := h.Get(name); got != value {
+				t.Fatalf("expected %s header %q, got %q", name, value, got)
+			}
+		}
+	}
+
+	// unset options use the defaults of the client
+	if _, err := client.UploadBlob(strings.NewReader("defaults")); err != nil {
+		t.Fatal(err)
+	}
+	expectHeaders(map[string]string{
+		"Swarm-Postage-Batch-Id": mock.BatchOkStr,
+		"Swarm-Redundancy-Level": "0",
+		"Swarm-Pin":              "true",
+		"Swarm-Encrypt":          "false",
+		"Swarm-Deferred-Upload":  "true",
+		"Swarm-Tag":              "",
+	})
+
+	// options of the call take precedence, the same way for every upload
+	tag, err := client.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchID := strings.Repeat("ab", 32)
+	opts := []blockstore.UploadOption{
+		blockstore.WithBatchID(batchID),
+		blockstore.WithRedundancyLevel(redundancy.MEDIUM),
+		blockstore.WithPin(false),
+		blockstore.WithEncrypt(true),
+		blockstore.WithTag(tag),
+	}
+	overridden := map[string]string{
+		"Swarm-Postage-Batch-Id": batchID,
+		"Swarm-Redundancy-Level": "1",
+		"Swarm-Pin":              "false",
+		"Swarm-Encrypt":          "true",
+		"Swarm-Tag":              strconv.FormatUint(uint64(tag), 10),
+	}
+	if _, err = client.UploadBlob(strings.NewReader("overridden"), opts...); err != nil {
+		t.Fatal(err)
+	}
+	expectHeaders(overridden)
+	ch, err := cac.New([]byte("overridden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.UploadChunk(ch, opts...); err != nil {
+		t.Fatal(err)
+	}
+	expectHeaders(overridden)
+	if _, err = client.UploadFileBzz([]byte("overridden"), "file.txt", opts...); err != nil {
+		t.Fatal(err)
+	}
+	expectHeaders(overridden)
+}
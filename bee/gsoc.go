@@ -0,0 +1,93 @@
+package bee
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	gsocSubscribeUrl = "/gsoc/subscribe/"
+
+	// maxGSOCProximity bounds mining, every extra bit doubles the expected number of keys to try
+	maxGSOCProximity = 24
+)
+
+var errGSOCProximity = fmt.Errorf("gsoc proximity can be at most %d", maxGSOCProximity)
+
+// GSOCMessage is a single update received on a GSOC subscription
+type GSOCMessage struct {
+	Address swarm.Address
+	Payload []byte
+}
+
+// MineGSOCKey looks for a private key whose single owner chunk with id lands in the neighbourhood
+// of target, that is its address shares at least proximity leading bits with target. Every writer
+// of the GSOC signs its updates with this key, so they all update the same chunk address.
+func MineGSOCKey(ctx context.Context, target swarm.Address, id []byte, proximity uint8) (*ecdsa.PrivateKey, error) {
+	if proximity > maxGSOCProximity {
+		return nil, errGSOCProximity
+	}
+	if len(id) != swarm.HashSize {
+		return nil, errors.New("gsoc id must be 32 bytes")
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		key, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			return nil, err
+		}
+		owner, err := crypto.NewEthereumAddress(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := soc.CreateAddress(id, owner)
+		if err != nil {
+			return nil, err
+		}
+		if swarm.Proximity(addr.Bytes(), target.Bytes()) >= proximity {
+			return key, nil
+		}
+	}
+}
+
+// GSOCAddress returns the chunk address updated by the GSOC of owner with id
+func GSOCAddress(owner common.Address, id []byte) (swarm.Address, error) {
+	return soc.CreateAddress(id, owner.Bytes())
+}
+
+// SendGSOC uploads payload as a new version of the GSOC signed by signer with id through UploadSOC
+func (s *Client) SendGSOC(ctx context.Context, signer crypto.Signer, id, payload []byte, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	return blockstore.WriteSOC(ctx, s, signer, id, payload, opts...)
+}
+
+// SubscribeGSOC receives every new version of the GSOC at address that reaches the node. The node
+// has to be in the neighbourhood of address. The websocket connection is reopened with backoff when
+// it drops and the channel is closed when ctx is done.
+func (s *Client) SubscribeGSOC(ctx context.Context, address swarm.Address) (<-chan *GSOCMessage, error) {
+	payloads, err := s.subscribeWs(ctx, gsocSubscribeUrl+address.String())
+	if err != nil {
+		return nil, err
+	}
+	messages := make(chan *GSOCMessage)
+	go func() {
+		defer close(messages)
+		for payload := range payloads {
+			select {
+			case messages <- &GSOCMessage{Address: address, Payload: payload}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return messages, nil
+}
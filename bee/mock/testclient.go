@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/asabya/swarm-blockstore/bee"
+	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	mockpost "github.com/ethersphere/bee/v2/pkg/postage/mock"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+)
+
+// NewTestServer starts a test bee server with the defaults the tests of this module share. Unset
+// options get a mock storer, a postage service that accepts every batch and a feed
+// factory on the storer. Redirects are always prevented.
+func NewTestServer(t *testing.T, o TestServerOptions) string {
+	t.Helper()
+	if o.Storer == nil {
+		o.Storer = mockstorer.New()
+	}
+	if o.Post == nil {
+		o.Post = mockpost.New(mockpost.WithAcceptAll())
+	}
+	if o.Feeds == nil {
+		o.Feeds = factory.New(o.Storer.Lookup())
+	}
+	o.PreventRedirect = true
+	return NewTestBeeServer(t, o)
+}
+
+// NewTestClient starts a test server with o and returns a client for it that stamps with BatchOk,
+// uploads without erasure coding and pins. The mock storer keeps pinned uploads, direct uploads
+// are pushed without being stored. opts are applied after these defaults.
+func NewTestClient(t *testing.T, o TestServerOptions, opts ...bee.Option) *bee.Client {
+	t.Helper()
+	opts = append([]bee.Option{bee.WithStamp(BatchOkStr), bee.WithRedundancy(redundancy.NONE), bee.WithPinning(true)}, opts...)
+	return bee.NewBeeClient(NewTestServer(t, o), opts...)
+}
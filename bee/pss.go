@@ -0,0 +1,144 @@
+package bee
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	pssSendUrl      = "/pss/send/"
+	pssSubscribeUrl = "/pss/subscribe/"
+
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+var errNoPSSTargets = errors.New("pss message needs at least one target")
+
+// SendPSS sends payload as a PSS message on topic to the nodes whose overlay addresses start with
+// one of the hex encoded targets. Targets are at most 3 bytes long. If recipient is nil the message
+// is encrypted for the topic, so every node subscribed to it can read the message.
+func (s *Client) SendPSS(ctx context.Context, topic string, targets []string, recipient *ecdsa.PublicKey, payload []byte) error {
+	if len(targets) == 0 {
+		return errNoPSSTargets
+	}
+	fullUrl := s.url + pssSendUrl + url.PathEscape(topic) + "/" + strings.Join(targets, ",")
+	if recipient != nil {
+		fullUrl += "?recipient=" + encodePublicKey(recipient)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullUrl, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Close = true
+	req.Header.Set(SwarmPostageBatchId, s.stamp)
+
+	response, err := s.Do(req)
+	if err != nil {
+		return err
+	}
+	// skipcq: GO-S2307
+	defer response.Body.Close()
+
+	respData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errors.New("error sending pss message")
+	}
+	if response.StatusCode != http.StatusCreated {
+		var beeErr *beeError
+		err = json.Unmarshal(respData, &beeErr)
+		if err != nil {
+			return errors.New(string(respData))
+		}
+		return errors.New(beeErr.Message)
+	}
+	return nil
+}
+
+// SubscribePSS receives the PSS messages on topic. The websocket connection is reopened with
+// backoff when it drops, messages sent while disconnected are lost. The channel is closed when
+// ctx is done.
+func (s *Client) SubscribePSS(ctx context.Context, topic string) (<-chan []byte, error) {
+	return s.subscribeWs(ctx, pssSubscribeUrl+url.PathEscape(topic))
+}
+
+// subscribeWs streams the binary messages of the websocket at path, reconnecting until ctx is done
+func (s *Client) subscribeWs(ctx context.Context, path string) (<-chan []byte, error) {
+	wsUrl := "ws" + strings.TrimPrefix(s.url, "http") + path
+	conn, err := s.dialWs(ctx, wsUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(chan []byte)
+	go func() {
+		defer close(messages)
+		delay := minReconnectDelay
+		for {
+			if conn != nil {
+				if readWs(ctx, conn, messages) {
+					delay = minReconnectDelay
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			conn, err = s.dialWs(ctx, wsUrl)
+			if err != nil {
+				delay *= 2
+				if delay > maxReconnectDelay {
+					delay = maxReconnectDelay
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
+func (s *Client) dialWs(ctx context.Context, wsUrl string) (*websocket.Conn, error) {
+	conn, response, err := websocket.DefaultDialer.DialContext(ctx, wsUrl, nil)
+	if response != nil {
+		response.Body.Close()
+	}
+	return conn, err
+}
+
+// readWs forwards messages from conn until it fails or ctx is done and reports whether any message arrived
+func readWs(ctx context.Context, conn *websocket.Conn, messages chan<- []byte) bool {
+	// closing the connection unblocks ReadMessage when ctx is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	received := false
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return received
+		}
+		received = true
+		select {
+		case messages <- data:
+		case <-ctx.Done():
+			return received
+		}
+	}
+}
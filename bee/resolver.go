@@ -0,0 +1,61 @@
+package bee
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var errInvalidName = errors.New("invalid bzz name")
+
+// Resolver resolves names like ENS domains to swarm references, ens.Resolver is one
+type Resolver interface {
+	Resolve(ctx context.Context, name string) (swarm.Address, error)
+}
+
+// WithResolver resolves the names of bzz downloads on the client instead of on the node
+func WithResolver(r Resolver) Option {
+	return func(c *Client) {
+		c.resolver = r
+	}
+}
+
+// DownloadBzzName downloads the bzz data of a name, like an ENS domain. The name is resolved by
+// the client's resolver if it has one, otherwise by the resolver of the node.
+func (s *Client) DownloadBzzName(name string, opts ...blockstore.DownloadOption) ([]byte, int, error) {
+	target, err := s.bzzTarget(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.downloadBzz(target, opts)
+}
+
+// DownloadFileBzzName downloads filename from the collection of a name, like an ENS domain. The name
+// is resolved by the client's resolver if it has one, otherwise by the resolver of the node.
+func (s *Client) DownloadFileBzzName(name, filename string, opts ...blockstore.DownloadOption) (io.ReadCloser, uint64, error) {
+	target, err := s.bzzTarget(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.downloadFileBzz(target, filename, opts)
+}
+
+// bzzTarget returns the reference of name if the client resolves names, or the name itself
+func (s *Client) bzzTarget(name string) (string, error) {
+	if name == "" || strings.ContainsRune(name, '/') {
+		return "", errInvalidName
+	}
+	if s.resolver == nil {
+		return url.PathEscape(name), nil
+	}
+	addr, err := s.resolver.Resolve(context.Background(), name)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
@@ -0,0 +1,436 @@
+// Package blockstoretest checks that an implementation of blockstore.Client behaves like a bee node.
+package blockstoretest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/tar"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// Factory returns a new, empty client for a test
+type Factory func(t *testing.T) blockstore.Client
+
+// Option configures the conformance suite
+type Option func(*config)
+
+type config struct {
+	tagCounts bool
+}
+
+// WithoutTagCounts skips the checks that uploads count in their tag, for clients whose backend
+// does not count tags, like a bee node with the mock storer of bee
+func WithoutTagCounts() Option {
+	return func(c *config) {
+		c.tagCounts = false
+	}
+}
+
+// TestClient runs the conformance suite against the clients of newClient, each subtest gets a new
+// client. The suite reads back what it uploads, so it uploads chunks with a tag and single owner
+// chunks pinned, a node pushes other chunk uploads to the network without storing them.
+func TestClient(t *testing.T, newClient Factory, opts ...Option) {
+	cfg := config{tagCounts: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	for _, tc := range []struct {
+		name string
+		test func(*testing.T, blockstore.Client)
+	}{
+		{"Chunk", testChunk},
+		{"SOC", testSOC},
+		{"Blob", testBlob},
+		{"File", testFile},
+		{"Collection", testCollection},
+		{"Tag", func(t *testing.T, c blockstore.Client) { testTag(t, c, cfg.tagCounts) }},
+		{"Pin", testPin},
+		{"FeedManifest", testFeedManifest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newClient(t))
+		})
+	}
+}
+
+func testChunk(t *testing.T, c blockstore.Client) {
+	ctx := context.Background()
+	tag, err := c.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := cac.New([]byte("conformance chunk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := c.UploadChunk(ch, blockstore.WithTag(tag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addr.Equal(ch.Address()) {
+		t.Fatalf("expected address %s, got %s", ch.Address(), addr)
+	}
+	got, err := c.DownloadChunk(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Address().Equal(addr) || !bytes.Equal(got.Data(), ch.Data()) {
+		t.Fatal("downloaded chunk differs")
+	}
+
+	missing, err := cac.New([]byte("missing chunk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.DownloadChunk(ctx, missing.Address()); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing chunk, got %v", err)
+	}
+}
+
+func testSOC(t *testing.T, c blockstore.Client) {
+	ctx := context.Background()
+	signer := newSigner(t)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := bytes.Repeat([]byte{1}, swarm.HashSize)
+	payload := []byte("conformance single owner chunk")
+
+	addr, err := blockstore.WriteSOC(ctx, c, signer, id, payload, blockstore.WithPin(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := soc.CreateAddress(id, owner.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addr.Equal(expected) {
+		t.Fatalf("expected address %s, got %s", expected, addr)
+	}
+	got, err := blockstore.ReadSOC(ctx, c, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("expected payload %q, got %q", payload, got)
+	}
+
+	// the signature of another id does not recover the owner
+	ch, err := cac.New(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := soc.New(bytes.Repeat([]byte{2}, swarm.HashSize), ch)
+	if _, err = s.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	invalidID := hex.EncodeToString(bytes.Repeat([]byte{3}, swarm.HashSize))
+	if _, err = c.UploadSOC(hex.EncodeToString(owner.Bytes()), invalidID, hex.EncodeToString(s.Signature()), ch.Data(), blockstore.WithPin(true)); err == nil {
+		t.Fatal("expected an invalid signature to be rejected")
+	}
+}
+
+func testBlob(t *testing.T, c blockstore.Client) {
+	for _, tc := range []struct {
+		name string
+		size int
+		opts []blockstore.UploadOption
+	}{
+		{name: "small", size: 1},
+		{name: "chunk", size: swarm.ChunkSize},
+		{name: "tree", size: 3*swarm.ChunkSize + 1},
+		{name: "encrypted", size: 2 * swarm.ChunkSize, opts: []blockstore.UploadOption{blockstore.WithEncrypt(true)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := randomData(t, tc.size)
+			ref, err := c.UploadBlob(bytes.NewReader(data), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, _, err := c.DownloadBlob(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("downloaded blob differs")
+			}
+		})
+	}
+
+	if _, _, err := c.DownloadBlob(swarm.NewAddress(bytes.Repeat([]byte{1}, swarm.HashSize))); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing blob, got %v", err)
+	}
+}
+
+func testFile(t *testing.T, c blockstore.Client) {
+	data := randomData(t, 2*swarm.ChunkSize)
+	ref, err := c.UploadFileBzz(data, "file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, size, err := c.DownloadFileBzz(ref, "file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) || size != uint64(len(data)) {
+		t.Fatalf("downloaded file differs, got %d bytes with length %d", len(got), size)
+	}
+	// the file is the index document of its manifest
+	got, _, err = c.DownloadBzz(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded index document differs")
+	}
+
+	if _, _, err = c.DownloadFileBzz(ref, "missing.bin"); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing file, got %v", err)
+	}
+}
+
+func testCollection(t *testing.T, c blockstore.Client) {
+	files := map[string]string{
+		"index.html":       "home",
+		"404.html":         "not found",
+		"about/index.html": "about",
+		"data/large.bin":   string(randomData(t, swarm.ChunkSize+1)),
+	}
+	s := tar.NewStream()
+	for p, data := range files {
+		if err := s.WriteItem(tar.CollectionItem{Path: p, Size: int64(len(data)), File: io.NopCloser(strings.NewReader(data))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.End(); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := c.UploadBzz(s, blockstore.WithIndexDocument("index.html"), blockstore.WithErrorDocument("404.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for p, data := range files {
+		r, size, err := c.DownloadFileBzz(ref, p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		if string(got) != data || size != uint64(len(data)) {
+			t.Fatalf("%s: downloaded file differs", p)
+		}
+	}
+	got, _, err := c.DownloadBzz(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != files["index.html"] {
+		t.Fatalf("expected the index document, got %q", got)
+	}
+	// a missing path serves the error document
+	r, _, err := c.DownloadFileBzz(ref, "missing.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got, err = io.ReadAll(r); err != nil || string(got) != files["404.html"] {
+		t.Fatalf("expected the error document, got %q, %v", got, err)
+	}
+}
+
+func testTag(t *testing.T, c blockstore.Client, counts bool) {
+	tag, err := c.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := c.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag == 0 || other == 0 || tag == other {
+		t.Fatalf("expected distinct tags, got %d and %d", tag, other)
+	}
+
+	checkTag := func(uid uint32) (int64, int64, int64) {
+		t.Helper()
+		total, processed, synced, err := c.GetTag(uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if synced < 0 || synced > processed || processed > total {
+			t.Fatalf("tag %d counts %d total, %d processed, %d synced", uid, total, processed, synced)
+		}
+		return total, processed, synced
+	}
+	checkTag(tag)
+	if _, err = c.UploadBlob(bytes.NewReader(randomData(t, 3*swarm.ChunkSize)), blockstore.WithTag(tag)); err != nil {
+		t.Fatal(err)
+	}
+	if total, processed, _ := checkTag(tag); counts && (total == 0 || processed == 0) {
+		t.Fatalf("expected the upload to count in tag %d, got %d total, %d processed", tag, total, processed)
+	}
+	// the upload counts in its own tag only
+	if total, _, _ := checkTag(other); total != 0 {
+		t.Fatalf("expected no chunks in tag %d, got %d", other, total)
+	}
+
+	if _, _, _, err = c.GetTag(tag + other + 1000); err == nil {
+		t.Fatal("expected an unknown tag to fail")
+	}
+}
+
+func testPin(t *testing.T, c blockstore.Client) {
+	pinned, err := c.UploadBlob(bytes.NewReader(randomData(t, swarm.ChunkSize)), blockstore.WithPin(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpinned, err := c.UploadBlob(bytes.NewReader(randomData(t, swarm.ChunkSize)), blockstore.WithPin(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkPin := func(ref swarm.Address, expected bool) {
+		t.Helper()
+		ok, err := c.IsPinned(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Fatalf("expected pinned %v for %s, got %v", expected, ref, ok)
+		}
+	}
+	checkPin(pinned, true)
+	checkPin(unpinned, false)
+
+	if err = c.DeleteReference(pinned); err != nil {
+		t.Fatal(err)
+	}
+	checkPin(pinned, false)
+	// unpinning a reference without a pin is not an error
+	if err = c.DeleteReference(unpinned); err != nil {
+		t.Fatal(err)
+	}
+	// a direct upload can not be pinned
+	_, err = c.UploadBlob(bytes.NewReader(randomData(t, swarm.ChunkSize)), blockstore.WithPin(true), blockstore.WithDeferred(false))
+	if !errors.Is(err, blockstore.ErrDirectPinOrTag) {
+		t.Fatalf("expected %v, got %v", blockstore.ErrDirectPinOrTag, err)
+	}
+}
+
+func testFeedManifest(t *testing.T, c blockstore.Client) {
+	ctx := context.Background()
+	signer := newSigner(t)
+	ownerAddr, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := hex.EncodeToString(ownerAddr.Bytes())
+	topicBytes := bytes.Repeat([]byte{4}, swarm.HashSize)
+	topic := hex.EncodeToString(topicBytes)
+
+	ref, err := c.CreateFeedManifest(owner, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := c.CreateFeedManifest(owner, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(again) {
+		t.Fatalf("expected the same feed manifest, got %s and %s", ref, again)
+	}
+
+	if _, _, _, err = c.GetLatestFeedManifest(owner, topic); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a feed without updates, got %v", err)
+	}
+
+	for i := uint64(0); i < 2; i++ {
+		content, err := c.UploadBlob(bytes.NewReader([]byte(fmt.Sprintf("update %d", i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFeedUpdate(ctx, t, c, signer, topicBytes, i, content)
+
+		latest, index, next, err := c.GetLatestFeedManifest(owner, topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !latest.Equal(content) {
+			t.Fatalf("expected update %d to reference %s, got %s", i, content, latest)
+		}
+		if index != indexString(i) || next != indexString(i+1) {
+			t.Fatalf("expected indexes %s and %s, got %s and %s", indexString(i), indexString(i+1), index, next)
+		}
+	}
+}
+
+// writeFeedUpdate uploads the update at index of the sequence feed of the signer with topic, the
+// way bee does: the wrapped chunk holds a timestamp and the reference
+func writeFeedUpdate(ctx context.Context, t *testing.T, c blockstore.Client, signer crypto.Signer, topic []byte, index uint64, ref swarm.Address) {
+	t.Helper()
+	indexBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(indexBytes, index)
+	id, err := crypto.LegacyKeccak256(append(append([]byte{}, topic...), indexBytes...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, 8, 8+len(ref.Bytes()))
+	binary.BigEndian.PutUint64(payload, uint64(time.Now().Unix()))
+	payload = append(payload, ref.Bytes()...)
+	if _, err = blockstore.WriteSOC(ctx, c, signer, id, payload, blockstore.WithPin(true)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func indexString(index uint64) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, index)
+	return hex.EncodeToString(b)
+}
+
+func newSigner(t *testing.T) crypto.Signer {
+	t.Helper()
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(pk)
+}
+
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,68 @@
+package blockstore
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrDocumentNotFound is returned when an index or error document is not part of the uploaded files
+var ErrDocumentNotFound = errors.New("document not found in upload")
+
+// BzzOptions are the website documents and the metadata of the manifest of a bzz upload
+type BzzOptions struct {
+	// IndexDocument is served for the root and for directory paths, it must not contain a slash
+	IndexDocument string
+	// ErrorDocument is served for paths that are not found
+	ErrorDocument string
+	// Metadata is added to the root entry of the manifest
+	Metadata map[string]string
+}
+
+// WithIndexDocument sets the document served for the root and for directory paths
+func WithIndexDocument(name string) UploadOption {
+	return func(o *UploadOptions) {
+		o.Bzz.IndexDocument = name
+	}
+}
+
+// WithErrorDocument sets the document served for paths that are not found
+func WithErrorDocument(p string) UploadOption {
+	return func(o *UploadOptions) {
+		o.Bzz.ErrorDocument = p
+	}
+}
+
+// WithMetadata adds metadata to the root entry of the manifest
+func WithMetadata(metadata map[string]string) UploadOption {
+	return func(o *UploadOptions) {
+		if o.Bzz.Metadata == nil {
+			o.Bzz.Metadata = make(map[string]string)
+		}
+		for k, v := range metadata {
+			o.Bzz.Metadata[k] = v
+		}
+	}
+}
+
+// Validate checks that the index and error documents are among the uploaded paths
+func (o *BzzOptions) Validate(paths []string) error {
+	if strings.ContainsRune(o.IndexDocument, '/') {
+		return fmt.Errorf("index document %q must not contain a slash", o.IndexDocument)
+	}
+	uploaded := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		uploaded[cleanPath(p)] = true
+	}
+	for _, doc := range []string{o.IndexDocument, o.ErrorDocument} {
+		if doc != "" && !uploaded[cleanPath(doc)] {
+			return fmt.Errorf("%w: %s", ErrDocumentNotFound, doc)
+		}
+	}
+	return nil
+}
+
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
@@ -2,27 +2,72 @@ package blockstore
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/asabya/swarm-blockstore/tar"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
-// Client is the interface for block store
-type Client interface {
-	CheckConnection() bool
-	UploadSOC(owner, id, signature, stamp, redundancyLevel string, pin bool, data []byte) (address swarm.Address, err error)
-	UploadChunk(tag uint32, ch swarm.Chunk, stamp, redundancyLevel string, pin bool) (address swarm.Address, err error)
-	UploadBlob(tag uint32, stamp, redundancyLevel string, pin, encrypt bool, data io.Reader) (address swarm.Address, err error)
-	UploadFileBzz(data []byte, fileName, stamp, redundancyLevel string, pin bool) (address swarm.Address, err error)
-	UploadBzz(data *tar.Stream, stamp, redundancyLevel string, pin bool) (address swarm.Address, err error)
-	DownloadChunk(ctx context.Context, address swarm.Address) (chunk swarm.Chunk, err error)
-	DownloadBlob(address swarm.Address) (data io.ReadCloser, respCode int, err error)
-	DownloadBzz(address swarm.Address) ([]byte, int, error)
-	DownloadFileBzz(address swarm.Address, filename string) (data io.ReadCloser, contentLength uint64, err error)
-	DeleteReference(address swarm.Address) error
+// ErrNotFound is returned when the requested data does not exist in the block store
+var ErrNotFound = errors.New("not found")
+
+// ChunkStore stores and retrieves content addressed chunks, it is all that chunk level helpers
+// such as putergetter.PutGetter and the manifest package need
+type ChunkStore interface {
+	UploadChunk(ch swarm.Chunk, opts ...UploadOption) (address swarm.Address, err error)
+	DownloadChunk(ctx context.Context, address swarm.Address, opts ...DownloadOption) (chunk swarm.Chunk, err error)
+}
+
+// SOCStore stores single owner chunks
+type SOCStore interface {
+	UploadSOC(owner, id, signature string, data []byte, opts ...UploadOption) (address swarm.Address, err error)
+}
+
+// BlobStore splits data of any size into chunks and joins it again
+type BlobStore interface {
+	UploadBlob(data io.Reader, opts ...UploadOption) (address swarm.Address, err error)
+	DownloadBlob(address swarm.Address, opts ...DownloadOption) (data io.ReadCloser, respCode int, err error)
+}
+
+// CollectionStore stores files and directories behind a manifest
+type CollectionStore interface {
+	UploadFileBzz(data []byte, fileName string, opts ...UploadOption) (address swarm.Address, err error)
+	UploadBzz(data *tar.Stream, opts ...UploadOption) (address swarm.Address, err error)
+	DownloadBzz(address swarm.Address, opts ...DownloadOption) ([]byte, int, error)
+	DownloadFileBzz(address swarm.Address, filename string, opts ...DownloadOption) (data io.ReadCloser, contentLength uint64, err error)
+}
+
+// Tagger counts the progress of uploads in tags. GetTag returns the chunks split for the
+// uploads of the tag, the chunks the node processed, by storing them or finding them stored
+// already, and the chunks it synced.
+type Tagger interface {
 	CreateTag(address swarm.Address) (uint32, error)
 	GetTag(tag uint32) (int64, int64, int64, error)
-	CreateFeedManifest(owner, topic, stamp string, pin bool) (address swarm.Address, err error)
+}
+
+// Pinner checks and removes the pins that pinned uploads create. IsPinned reports whether the
+// node pins a reference, a reference without a pin is not an error.
+type Pinner interface {
+	IsPinned(address swarm.Address) (bool, error)
+	DeleteReference(address swarm.Address) error
+}
+
+// FeedManifests creates feed manifests and looks up the latest update of a feed
+type FeedManifests interface {
+	CreateFeedManifest(owner, topic string, opts ...UploadOption) (address swarm.Address, err error)
 	GetLatestFeedManifest(owner, topic string) (address swarm.Address, index, nextIndex string, err error)
 }
+
+// Client is the interface for block store. Helpers that need less take one of the interfaces it is
+// built from, and check for the others at runtime.
+type Client interface {
+	CheckConnection() bool
+	ChunkStore
+	SOCStore
+	BlobStore
+	CollectionStore
+	Tagger
+	Pinner
+	FeedManifests
+}
@@ -0,0 +1,61 @@
+package blockstore
+
+import (
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy/getter"
+)
+
+// DownloadOption tunes how the node retrieves the data of a download
+type DownloadOption func(*DownloadOptions)
+
+// DownloadOptions are the retrieval settings of a download. Unset fields use the defaults of
+// the node. Chunk downloads only use Cache.
+type DownloadOptions struct {
+	// RedundancyStrategy selects which chunks and parities of erasure coded data are fetched,
+	// getter.RACE fetches all of them at once for the lowest latency
+	RedundancyStrategy *getter.Strategy
+	// Fallback retries with getter.RACE when the redundancy strategy fails
+	Fallback *bool
+	// ChunkRetrievalTimeout bounds the retrieval of a single chunk
+	ChunkRetrievalTimeout time.Duration
+	// Cache keeps the retrieved chunks in the cache of the node
+	Cache *bool
+}
+
+// WithRedundancyStrategy sets which chunks and parities of erasure coded data are fetched
+func WithRedundancyStrategy(strategy getter.Strategy) DownloadOption {
+	return func(o *DownloadOptions) {
+		o.RedundancyStrategy = &strategy
+	}
+}
+
+// WithFallback sets whether the download is retried with getter.RACE when the redundancy strategy fails
+func WithFallback(fallback bool) DownloadOption {
+	return func(o *DownloadOptions) {
+		o.Fallback = &fallback
+	}
+}
+
+// WithChunkRetrievalTimeout bounds the retrieval of a single chunk
+func WithChunkRetrievalTimeout(timeout time.Duration) DownloadOption {
+	return func(o *DownloadOptions) {
+		o.ChunkRetrievalTimeout = timeout
+	}
+}
+
+// WithCache sets whether the node caches the retrieved chunks, archival reads can skip it
+func WithCache(cache bool) DownloadOption {
+	return func(o *DownloadOptions) {
+		o.Cache = &cache
+	}
+}
+
+// NewDownloadOptions returns the options set by opts
+func NewDownloadOptions(opts ...DownloadOption) *DownloadOptions {
+	o := &DownloadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,143 @@
+// Package ens resolves ENS names to swarm references on the client, without asking the bee node
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	goens "github.com/wealdtech/go-ens/v3"
+)
+
+const (
+	swarmContentHashPrefix = "bzz://"
+
+	registryABI = `[{"name":"resolver","type":"function","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]}]`
+	resolverABI = `[{"name":"contenthash","type":"function","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"bytes"}]}]`
+)
+
+// DefaultRegistry is the address of the ENS registry on Ethereum mainnet, the same one bee uses
+var DefaultRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ErrInvalidContentHash is returned when the content hash of a name is not a swarm reference
+var ErrInvalidContentHash = errors.New("invalid swarm content hash")
+
+var (
+	registryContract = mustParseABI(registryABI)
+	resolverContract = mustParseABI(resolverABI)
+)
+
+// Resolver looks up the content hash of ENS names through an Ethereum endpoint
+type Resolver struct {
+	caller   ethereum.ContractCaller
+	registry common.Address
+	close    func()
+}
+
+// Option configures a Resolver
+type Option func(*Resolver)
+
+// WithRegistry sets the address of the ENS registry, for test networks and local chains
+func WithRegistry(registry common.Address) Option {
+	return func(r *Resolver) {
+		r.registry = registry
+	}
+}
+
+// NewResolver returns a Resolver that calls the ENS contracts through caller, which can be an
+// ethclient.Client or a simulated backend
+func NewResolver(caller ethereum.ContractCaller, opts ...Option) *Resolver {
+	r := &Resolver{
+		caller:   caller,
+		registry: DefaultRegistry,
+		close:    func() {},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Dial returns a Resolver that calls the ENS contracts through the RPC endpoint
+func Dial(ctx context.Context, endpoint string, opts ...Option) (*Resolver, error) {
+	client, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	r := NewResolver(client, opts...)
+	r.close = client.Close
+	return r, nil
+}
+
+// Resolve returns the swarm reference in the content hash record of name. It returns
+// blockstore.ErrNotFound if the name has no resolver.
+func (r *Resolver) Resolve(ctx context.Context, name string) (swarm.Address, error) {
+	node, err := goens.NameHash(name)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	result, err := r.call(ctx, registryContract, r.registry, "resolver", node)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("ens registry: %w", err)
+	}
+	resolver, ok := result.(common.Address)
+	if !ok || resolver == (common.Address{}) {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %s", blockstore.ErrNotFound, name)
+	}
+
+	result, err = r.call(ctx, resolverContract, resolver, "contenthash", node)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("ens resolver: %w", err)
+	}
+	contentHash, ok := result.([]byte)
+	if !ok || len(contentHash) == 0 {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %s", blockstore.ErrNotFound, name)
+	}
+	hash, err := goens.ContenthashToString(contentHash)
+	if err != nil || !strings.HasPrefix(hash, swarmContentHashPrefix) {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %x", ErrInvalidContentHash, contentHash)
+	}
+	addr, err := swarm.ParseHexAddress(strings.TrimPrefix(hash, swarmContentHashPrefix))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %s", ErrInvalidContentHash, hash)
+	}
+	return addr, nil
+}
+
+// Close closes the connection of a Resolver returned by Dial
+func (r *Resolver) Close() {
+	r.close()
+}
+
+// call calls the view method of the contract at address with node and returns its only result
+func (r *Resolver) call(ctx context.Context, contract abi.ABI, address common.Address, method string, node [32]byte) (interface{}, error) {
+	input, err := contract.Pack(method, node)
+	if err != nil {
+		return nil, err
+	}
+	output, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &address, Data: input}, nil)
+	if err != nil {
+		return nil, err
+	}
+	results, err := contract.Unpack(method, output)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func mustParseABI(definition string) abi.ABI {
+	a, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
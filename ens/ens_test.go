@@ -0,0 +1,130 @@
+package ens_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/ens"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	goens "github.com/wealdtech/go-ens/v3"
+)
+
+// evmBackend runs contract calls on a local EVM. The simulated backend of go-ethereum does
+// the same, but does not link with every Go release.
+type evmBackend struct {
+	cfg *runtime.Config
+}
+
+func newEVMBackend(t *testing.T, contracts map[common.Address][]byte) *evmBackend {
+	t.Helper()
+	db, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for addr, code := range contracts {
+		db.SetCode(addr, code)
+	}
+	return &evmBackend{cfg: &runtime.Config{State: db}}
+}
+
+func (b *evmBackend) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	out, _, err := runtime.Call(*msg.To, msg.Data, b.cfg)
+	return out, err
+}
+
+// answerCode is the runtime code of a contract that returns data for calls of the method with
+// signature on the namehash of name, and reverts on any other call
+func answerCode(t *testing.T, signature, name string, data []byte) []byte {
+	t.Helper()
+	node, err := goens.NameHash(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PUSH1 0, CALLDATALOAD, PUSH1 224, SHR, PUSH4 selector, EQ
+	code := append([]byte{0x60, 0, 0x35, 0x60, 0xe0, 0x1c, 0x63}, crypto.Keccak256([]byte(signature))[:4]...)
+	code = append(code, 0x14)
+	// PUSH1 4, CALLDATALOAD, PUSH32 node, EQ, AND
+	code = append(code, 0x60, 4, 0x35, 0x7f)
+	code = append(code, node[:]...)
+	code = append(code, 0x14, 0x16)
+	// PUSH1 answer, JUMPI, PUSH1 0, DUP1, REVERT
+	answer := byte(len(code) + 7)
+	code = append(code, 0x60, answer, 0x57, 0x60, 0, 0x80, 0xfd)
+	// answer: JUMPDEST, PUSH2 len, DUP1, PUSH1 offset, PUSH1 0, CODECOPY, PUSH1 0, RETURN, data
+	offset := answer + 13
+	code = append(code, 0x5b, 0x61, byte(len(data)>>8), byte(len(data)), 0x80, 0x60, offset, 0x60, 0, 0x39, 0x60, 0, 0xf3)
+	return append(code, data...)
+}
+
+func encode(t *testing.T, typ string, value interface{}) []byte {
+	t.Helper()
+	ty, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := abi.Arguments{{Type: ty}}.Pack(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestResolve(t *testing.T) {
+	ref := swarm.MustParseHexAddress("f6ce3b2f11c1c7b5e0c56b4e8e0ef8b5d1b4a7a2c1a0e1f2d3c4b5a697887960")
+	contentHash, err := goens.StringToContenthash("bzz://" + ref.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := common.HexToAddress("0x1000")
+	emptyRegistry := common.HexToAddress("0x1001")
+	resolver := common.HexToAddress("0x2000")
+	ipfsRegistry := common.HexToAddress("0x1002")
+	ipfsResolver := common.HexToAddress("0x2001")
+	ipfsHash, err := goens.StringToContenthash("/ipfs/QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := newEVMBackend(t, map[common.Address][]byte{
+		registry:      answerCode(t, "resolver(bytes32)", "site.eth", encode(t, "address", resolver)),
+		emptyRegistry: answerCode(t, "resolver(bytes32)", "missing.eth", encode(t, "address", common.Address{})),
+		resolver:      answerCode(t, "contenthash(bytes32)", "site.eth", encode(t, "bytes", contentHash)),
+		ipfsRegistry:  answerCode(t, "resolver(bytes32)", "ipfs.eth", encode(t, "address", ipfsResolver)),
+		ipfsResolver:  answerCode(t, "contenthash(bytes32)", "ipfs.eth", encode(t, "bytes", ipfsHash)),
+	})
+	ctx := context.Background()
+
+	addr, err := ens.NewResolver(backend, ens.WithRegistry(registry)).Resolve(ctx, "site.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addr.Equal(ref) {
+		t.Fatalf("expected %s, got %s", ref, addr)
+	}
+
+	// the contracts only answer for the namehash of their name
+	if _, err = ens.NewResolver(backend, ens.WithRegistry(registry)).Resolve(ctx, "other.eth"); err == nil {
+		t.Fatal("expected the registry to reject another name")
+	}
+
+	_, err = ens.NewResolver(backend, ens.WithRegistry(emptyRegistry)).Resolve(ctx, "missing.eth")
+	if !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_, err = ens.NewResolver(backend, ens.WithRegistry(ipfsRegistry)).Resolve(ctx, "ipfs.eth")
+	if !errors.Is(err, ens.ErrInvalidContentHash) {
+		t.Fatalf("expected ErrInvalidContentHash, got %v", err)
+	}
+}
@@ -0,0 +1,471 @@
+// Package fault wraps a blockstore.Client and injects failures into its calls, to test retry and
+// failover code. Failures are drawn from random sources seeded by the caller, one for each method,
+// so the same calls of a method in the same order fail the same way, however they interleave with
+// the calls of other methods.
+package fault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	bstar "github.com/asabya/swarm-blockstore/tar"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var (
+	// ErrInjected is returned by the calls that fail on purpose
+	ErrInjected = errors.New("injected fault")
+	// ErrClosed is returned by stalled calls without a context when the client is closed
+	ErrClosed = errors.New("fault client closed")
+)
+
+// Method is a method of blockstore.Client that faults are injected into
+type Method string
+
+// The methods of blockstore.Client
+const (
+	CheckConnection       Method = "CheckConnection"
+	UploadChunk           Method = "UploadChunk"
+	DownloadChunk         Method = "DownloadChunk"
+	UploadSOC             Method = "UploadSOC"
+	UploadBlob            Method = "UploadBlob"
+	DownloadBlob          Method = "DownloadBlob"
+	UploadFileBzz         Method = "UploadFileBzz"
+	UploadBzz             Method = "UploadBzz"
+	DownloadBzz           Method = "DownloadBzz"
+	DownloadFileBzz       Method = "DownloadFileBzz"
+	CreateTag             Method = "CreateTag"
+	GetTag                Method = "GetTag"
+	IsPinned              Method = "IsPinned"
+	DeleteReference       Method = "DeleteReference"
+	CreateFeedManifest    Method = "CreateFeedManifest"
+	GetLatestFeedManifest Method = "GetLatestFeedManifest"
+)
+
+var methods = []Method{
+	CheckConnection, UploadChunk, DownloadChunk, UploadSOC, UploadBlob, DownloadBlob, UploadFileBzz, UploadBzz,
+	DownloadBzz, DownloadFileBzz, CreateTag, GetTag, IsPinned, DeleteReference, CreateFeedManifest, GetLatestFeedManifest,
+}
+
+// Latency draws the delay of a call
+type Latency func(r *rand.Rand) time.Duration
+
+// FixedLatency delays every call by d
+func FixedLatency(d time.Duration) Latency {
+	return func(*rand.Rand) time.Duration {
+		return d
+	}
+}
+
+// UniformLatency delays calls by a duration between min and max
+func UniformLatency(min, max time.Duration) Latency {
+	return func(r *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.Int63n(int64(max-min)))
+	}
+}
+
+// NormalLatency delays calls by a normally distributed duration, negative draws do not delay
+func NormalLatency(mean, stddev time.Duration) Latency {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(math.Max(0, r.NormFloat64()*float64(stddev)+float64(mean)))
+	}
+}
+
+// ExponentialLatency delays calls by an exponentially distributed duration, most calls are fast
+// and a few are slow
+func ExponentialLatency(mean time.Duration) Latency {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(r.ExpFloat64() * float64(mean))
+	}
+}
+
+// faults are the failures injected into the calls of a method, rates are between 0 and 1. They
+// are drawn from the random source of the method.
+type faults struct {
+	rand        *rand.Rand
+	latency     Latency
+	errorRate   float64
+	stallRate   float64
+	dropRate    float64
+	partialRate float64
+	corruptRate float64
+}
+
+// Option configures the faults of a Client
+type Option func(*Client)
+
+// WithLatency delays the calls of methods, or of all methods if none are given
+func WithLatency(latency Latency, methods ...Method) Option {
+	return func(c *Client) {
+		c.set(methods, func(f *faults) { f.latency = latency })
+	}
+}
+
+// WithErrorRate fails a share of the calls of methods with ErrInjected, or of all methods if none
+// are given. The wrapped client does not see the failed calls.
+func WithErrorRate(rate float64, methods ...Method) Option {
+	return func(c *Client) {
+		c.set(methods, func(f *faults) { f.errorRate = rate })
+	}
+}
+
+// WithStallRate blocks a share of the calls of methods, or of all methods if none are given, until
+// their context is cancelled. Methods without a context block until the client is closed.
+func WithStallRate(rate float64, methods ...Method) Option {
+	return func(c *Client) {
+		c.set(methods, func(f *faults) { f.stallRate = rate })
+	}
+}
+
+// WithDropRate drops a share of the uploads of methods, or of all upload methods if none are
+// given. A dropped upload reports success with the reference it would have had, but the wrapped
+// client never stores it. The reference is computed with the file pipeline of bee from the options
+// of the call, without keeping the data. It does not match for encrypted uploads, which get a
+// random key, or for uploads that rely on the defaults of the wrapped client, such as its
+// redundancy level or access control. Uploads with access control set by the call are never dropped.
+func WithDropRate(rate float64, methods ...Method) Option {
+	return func(c *Client) {
+		if len(methods) == 0 {
+			methods = []Method{UploadChunk, UploadSOC, UploadBlob, UploadFileBzz, UploadBzz, CreateFeedManifest}
+		}
+		c.set(methods, func(f *faults) { f.dropRate = rate })
+	}
+}
+
+// WithPartialReadRate cuts a share of the blobs from DownloadBlob short, reading past the cut
+// fails with io.ErrUnexpectedEOF
+func WithPartialReadRate(rate float64) Option {
+	return func(c *Client) {
+		c.set([]Method{DownloadBlob}, func(f *faults) { f.partialRate = rate })
+	}
+}
+
+// WithCorruptionRate flips a byte in the data of a share of the chunks from DownloadChunk, the
+// address is unchanged
+func WithCorruptionRate(rate float64) Option {
+	return func(c *Client) {
+		c.set([]Method{DownloadChunk}, func(f *faults) { f.corruptRate = rate })
+	}
+}
+
+// Client is a blockstore.Client that injects faults into the calls of the client it wraps
+type Client struct {
+	client blockstore.Client
+	faults map[Method]*faults
+	seed   int64
+
+	// mtx guards the random sources of the faults
+	mtx sync.Mutex
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewClient wraps client, the faults set by opts are drawn from random sources derived from seed
+func NewClient(client blockstore.Client, seed int64, opts ...Option) *Client {
+	c := &Client{
+		client: client,
+		faults: make(map[Method]*faults),
+		seed:   seed,
+		closed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Close releases the stalled calls of methods without a context
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *Client) set(ms []Method, fn func(*faults)) {
+	if len(ms) == 0 {
+		ms = methods
+	}
+	for _, m := range ms {
+		f, ok := c.faults[m]
+		if !ok {
+			f = &faults{rand: rand.New(rand.NewSource(methodSeed(c.seed, m)))}
+			c.faults[m] = f
+		}
+		fn(f)
+	}
+}
+
+// methodSeed derives the seed of the random source of m from seed
+func methodSeed(seed int64, m Method) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m))
+	return seed ^ int64(h.Sum64())
+}
+
+// hit draws whether a fault with rate happens, it does not draw for rates that never or always hit
+func (f *faults) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	return f.rand.Float64() < rate
+}
+
+// chance draws a fault of m with the rate that rate selects
+func (c *Client) chance(m Method, rate func(*faults) float64) bool {
+	f, ok := c.faults[m]
+	if !ok {
+		return false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return f.hit(rate(f))
+}
+
+// intn draws a number in [0, n) for a fault of m
+func (c *Client) intn(m Method, n int) int {
+	f := c.faults[m]
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return f.rand.Intn(n)
+}
+
+// before delays, stalls or fails a call of m before it reaches the wrapped client
+func (c *Client) before(ctx context.Context, m Method) error {
+	f, ok := c.faults[m]
+	if !ok {
+		return nil
+	}
+	c.mtx.Lock()
+	var delay time.Duration
+	if f.latency != nil {
+		delay = f.latency(f.rand)
+	}
+	stall := f.hit(f.stallRate)
+	fail := f.hit(f.errorRate)
+	c.mtx.Unlock()
+
+	if stall {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.closed:
+			return ErrClosed
+		}
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fail {
+		return fmt.Errorf("%w: %s", ErrInjected, m)
+	}
+	return nil
+}
+
+// drop draws whether an upload of m with opts is dropped, uploads with access control are not
+func (c *Client) drop(m Method, opts []blockstore.UploadOption) bool {
+	if o := blockstore.NewUploadOptions(opts...); o.ACT != nil && *o.ACT {
+		return false
+	}
+	return c.chance(m, func(f *faults) float64 { return f.dropRate })
+}
+
+// CheckConnection reports a failed connection for failed calls
+func (c *Client) CheckConnection() bool {
+	if err := c.before(context.Background(), CheckConnection); err != nil {
+		return false
+	}
+	return c.client.CheckConnection()
+}
+
+// UploadChunk uploads ch to the wrapped client, a dropped upload returns the address of ch
+func (c *Client) UploadChunk(ch swarm.Chunk, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	if err := c.before(context.Background(), UploadChunk); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if c.drop(UploadChunk, opts) {
+		return newReference(opts).done(ch.Address())
+	}
+	return c.client.UploadChunk(ch, opts...)
+}
+
+// DownloadChunk downloads the chunk from the wrapped client, stalls and delays end when ctx is cancelled
+func (c *Client) DownloadChunk(ctx context.Context, address swarm.Address, opts ...blockstore.DownloadOption) (swarm.Chunk, error) {
+	if err := c.before(ctx, DownloadChunk); err != nil {
+		return nil, err
+	}
+	ch, err := c.client.DownloadChunk(ctx, address, opts...)
+	if err != nil || len(ch.Data()) == 0 {
+		return ch, err
+	}
+	if c.chance(DownloadChunk, func(f *faults) float64 { return f.corruptRate }) {
+		data := bytes.Clone(ch.Data())
+		data[c.intn(DownloadChunk, len(data))] ^= 0xff
+		ch = swarm.NewChunk(ch.Address(), data)
+	}
+	return ch, nil
+}
+
+// UploadSOC uploads the single owner chunk to the wrapped client
+func (c *Client) UploadSOC(owner, id, signature string, data []byte, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	if err := c.before(context.Background(), UploadSOC); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if c.drop(UploadSOC, opts) {
+		return newReference(opts).soc(owner, id, signature, data)
+	}
+	return c.client.UploadSOC(owner, id, signature, data, opts...)
+}
+
+// UploadBlob uploads data to the wrapped client
+func (c *Client) UploadBlob(data io.Reader, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	if err := c.before(context.Background(), UploadBlob); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if c.drop(UploadBlob, opts) {
+		return newReference(opts).blob(data)
+	}
+	return c.client.UploadBlob(data, opts...)
+}
+
+// DownloadBlob downloads the blob from the wrapped client. A partial read returns the blob up to
+// a random cut, followed by io.ErrUnexpectedEOF.
+func (c *Client) DownloadBlob(address swarm.Address, opts ...blockstore.DownloadOption) (io.ReadCloser, int, error) {
+	if err := c.before(context.Background(), DownloadBlob); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	r, code, err := c.client.DownloadBlob(address, opts...)
+	if err != nil {
+		return r, code, err
+	}
+	if !c.chance(DownloadBlob, func(f *faults) float64 { return f.partialRate }) {
+		return r, code, nil
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, code, err
+	}
+	if len(data) > 0 {
+		data = data[:c.intn(DownloadBlob, len(data))]
+	}
+	return io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{io.ErrUnexpectedEOF})), code, nil
+}
+
+// UploadFileBzz uploads the file to the wrapped client
+func (c *Client) UploadFileBzz(data []byte, fileName string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	if err := c.before(context.Background(), UploadFileBzz); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if c.drop(UploadFileBzz, opts) {
+		return newReference(opts).file(data, fileName)
+	}
+	return c.client.UploadFileBzz(data, fileName, opts...)
+}
+
+// UploadBzz uploads the collection to the wrapped client
+func (c *Client) UploadBzz(data *bstar.Stream, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	if err := c.before(context.Background(), UploadBzz); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if c.drop(UploadBzz, opts) {
+		return newReference(opts).bzz(data)
+	}
+	return c.client.UploadBzz(data, opts...)
+}
+
+// DownloadBzz downloads the index document from the wrapped client
+func (c *Client) DownloadBzz(address swarm.Address, opts ...blockstore.DownloadOption) ([]byte, int, error) {
+	if err := c.before(context.Background(), DownloadBzz); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return c.client.DownloadBzz(address, opts...)
+}
+
+// DownloadFileBzz downloads the file from the wrapped client
+func (c *Client) DownloadFileBzz(address swarm.Address, filename string, opts ...blockstore.DownloadOption) (io.ReadCloser, uint64, error) {
+	if err := c.before(context.Background(), DownloadFileBzz); err != nil {
+		return nil, 0, err
+	}
+	return c.client.DownloadFileBzz(address, filename, opts...)
+}
+
+// CreateTag creates a tag on the wrapped client
+func (c *Client) CreateTag(address swarm.Address) (uint32, error) {
+	if err := c.before(context.Background(), CreateTag); err != nil {
+		return 0, err
+	}
+	return c.client.CreateTag(address)
+}
+
+// GetTag returns the counts of tag from the wrapped client
+func (c *Client) GetTag(tag uint32) (int64, int64, int64, error) {
+	if err := c.before(context.Background(), GetTag); err != nil {
+		return 0, 0, 0, err
+	}
+	return c.client.GetTag(tag)
+}
+
+// IsPinned reports whether the wrapped client pins address
+func (c *Client) IsPinned(address swarm.Address) (bool, error) {
+	if err := c.before(context.Background(), IsPinned); err != nil {
+		return false, err
+	}
+	return c.client.IsPinned(address)
+}
+
+// DeleteReference unpins address on the wrapped client
+func (c *Client) DeleteReference(address swarm.Address) error {
+	if err := c.before(context.Background(), DeleteReference); err != nil {
+		return err
+	}
+	return c.client.DeleteReference(address)
+}
+
+// CreateFeedManifest uploads the feed manifest to the wrapped client
+func (c *Client) CreateFeedManifest(owner, topic string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	if err := c.before(context.Background(), CreateFeedManifest); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if c.drop(CreateFeedManifest, opts) {
+		return newReference(opts).feedManifest(owner, topic)
+	}
+	return c.client.CreateFeedManifest(owner, topic, opts...)
+}
+
+// GetLatestFeedManifest looks up the latest feed update on the wrapped client
+func (c *Client) GetLatestFeedManifest(owner, topic string) (swarm.Address, string, string, error) {
+	if err := c.before(context.Background(), GetLatestFeedManifest); err != nil {
+		return swarm.ZeroAddress, "", "", err
+	}
+	return c.client.GetLatestFeedManifest(owner, topic)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
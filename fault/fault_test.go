@@ -0,0 +1,245 @@
+package fault_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/bee/mock"
+	"github.com/asabya/swarm-blockstore/blockstoretest"
+	"github.com/asabya/swarm-blockstore/fault"
+	"github.com/asabya/swarm-blockstore/memory"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestConformance(t *testing.T) {
+	// without faults the wrapper passes every call through
+	blockstoretest.TestClient(t, func(*testing.T) blockstore.Client {
+		return fault.NewClient(memory.NewClient(), 1)
+	})
+}
+
+func TestErrorRate(t *testing.T) {
+	failures := func(seed int64, others int) []bool {
+		c := fault.NewClient(memory.NewClient(), seed, fault.WithErrorRate(0.5, fault.CreateTag, fault.GetTag))
+		var failed []bool
+		for i := 0; i < 64; i++ {
+			for j := 0; j < others*(i%3); j++ {
+				_, _, _, _ = c.GetTag(1)
+			}
+			_, err := c.CreateTag(swarm.ZeroAddress)
+			if err != nil && !errors.Is(err, fault.ErrInjected) {
+				t.Fatal(err)
+			}
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+	first, again, other := failures(1, 0), failures(1, 0), failures(2, 0)
+	interleaved := failures(1, 1)
+	count := 0
+	for i := range first {
+		if first[i] != again[i] {
+			t.Fatalf("call %d: expected the same failures for the same seed", i)
+		}
+		if first[i] != interleaved[i] {
+			t.Fatalf("call %d: expected the same failures when calls of other methods interleave", i)
+		}
+		if first[i] {
+			count++
+		}
+	}
+	if count == 0 || count == len(first) {
+		t.Fatalf("expected some calls to fail, %d of %d failed", count, len(first))
+	}
+	same := true
+	for i := range first {
+		same = same && first[i] == other[i]
+	}
+	if same {
+		t.Fatal("expected other failures for another seed")
+	}
+
+	// other methods do not fail
+	c := fault.NewClient(memory.NewClient(), 1, fault.WithErrorRate(1, fault.CreateTag))
+	if !c.CheckConnection() {
+		t.Fatal("expected the connection check to succeed")
+	}
+	if _, err := c.CreateTag(swarm.ZeroAddress); !errors.Is(err, fault.ErrInjected) {
+		t.Fatalf("expected ErrInjected, got %v", err)
+	}
+}
+
+func TestDropRate(t *testing.T) {
+	store := memory.NewClient()
+	c := fault.NewClient(store, 1, fault.WithDropRate(1))
+	data := bytes.Repeat([]byte{1}, 3*swarm.ChunkSize)
+	ref, err := c.UploadBlob(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := memory.NewClient().UploadBlob(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(expected) {
+		t.Fatalf("expected reference %s, got %s", expected, ref)
+	}
+	if _, _, err = store.DownloadBlob(ref); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected the dropped blob to be missing, got %v", err)
+	}
+
+	ch, err := cac.New([]byte("dropped"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.UploadChunk(ch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.DownloadChunk(context.Background(), ch.Address()); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected the dropped chunk to be missing, got %v", err)
+	}
+
+	owner, topic := "0x8d3766440f0d7b949a5e32995d09619a7f86e632", hex.EncodeToString(make([]byte, 32))
+	if ref, err = c.CreateFeedManifest(owner, topic); err != nil {
+		t.Fatal(err)
+	}
+	if expected, err = memory.NewClient().CreateFeedManifest(owner, topic); err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(expected) {
+		t.Fatalf("expected feed manifest %s, got %s", expected, ref)
+	}
+}
+
+func TestDropRateMatchesBee(t *testing.T) {
+	st := mockstorer.New()
+	beeClient := mock.NewTestClient(t, mock.TestServerOptions{Storer: st})
+	c := fault.NewClient(beeClient, 1, fault.WithDropRate(1))
+	ctx := context.Background()
+	data := bytes.Repeat([]byte{1}, 3*swarm.ChunkSize)
+
+	tag, err := beeClient.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []blockstore.UploadOption{blockstore.WithTag(tag), blockstore.WithMetadata(map[string]string{"a": "b"})}
+	ref, err := c.UploadFileBzz(data, "file.txt", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := st.ChunkStore().Has(ctx, ref); has {
+		t.Fatal("expected the dropped file to be missing")
+	}
+	expected, err := beeClient.UploadFileBzz(data, "file.txt", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(expected) {
+		t.Fatalf("expected reference %s, got %s", expected, ref)
+	}
+}
+
+func TestPartialReadRate(t *testing.T) {
+	store := memory.NewClient()
+	data := bytes.Repeat([]byte{1}, 2*swarm.ChunkSize)
+	ref, err := store.UploadBlob(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := fault.NewClient(store, 1, fault.WithPartialReadRate(1))
+	r, _, err := c.DownloadBlob(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if len(got) >= len(data) || !bytes.Equal(got, data[:len(got)]) {
+		t.Fatalf("expected a prefix of the blob, got %d bytes", len(got))
+	}
+}
+
+func TestCorruptionRate(t *testing.T) {
+	store := memory.NewClient()
+	ch, err := cac.New([]byte("chunk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.UploadChunk(ch); err != nil {
+		t.Fatal(err)
+	}
+	c := fault.NewClient(store, 1, fault.WithCorruptionRate(1))
+	got, err := c.DownloadChunk(context.Background(), ch.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Address().Equal(ch.Address()) || bytes.Equal(got.Data(), ch.Data()) {
+		t.Fatal("expected corrupted data at the same address")
+	}
+	if cac.Valid(got) {
+		t.Fatal("expected the corrupted chunk to be invalid")
+	}
+	// the wrapped chunk is not changed
+	if orig, err := store.DownloadChunk(context.Background(), ch.Address()); err != nil || !cac.Valid(orig) {
+		t.Fatalf("expected the stored chunk to be valid, got %v", err)
+	}
+}
+
+func TestStallRate(t *testing.T) {
+	c := fault.NewClient(memory.NewClient(), 1, fault.WithStallRate(1))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.DownloadChunk(ctx, swarm.RandAddress(t)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := c.CreateTag(swarm.ZeroAddress)
+		errC <- err
+	}()
+	select {
+	case err := <-errC:
+		t.Fatalf("expected the call to stall, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errC; !errors.Is(err, fault.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	c := fault.NewClient(memory.NewClient(), 1, fault.WithLatency(fault.FixedLatency(50*time.Millisecond), fault.GetTag))
+	tag, err := c.CreateTag(swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, _, _, err = c.GetTag(tag); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected a delay of 50ms, got %s", elapsed)
+	}
+
+	// a delay ends when the context is cancelled
+	c = fault.NewClient(memory.NewClient(), 1, fault.WithLatency(fault.UniformLatency(time.Hour, 2*time.Hour)))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = c.DownloadChunk(ctx, swarm.RandAddress(t)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
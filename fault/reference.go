@@ -0,0 +1,232 @@
+package fault
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	bsmanifest "github.com/asabya/swarm-blockstore/manifest"
+	bstar "github.com/asabya/swarm-blockstore/tar"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	feedMetadataEntryOwner = "swarm-feed-owner"
+	feedMetadataEntryTopic = "swarm-feed-topic"
+	feedMetadataEntryType  = "swarm-feed-type"
+
+	// fileContentType is the content type bee.Client uploads single files with
+	fileContentType = "application/json"
+)
+
+// discard drops the data chunks of a dropped upload
+var discard = storage.PutterFunc(func(context.Context, swarm.Chunk) error { return nil })
+
+// reference computes the reference of a dropped upload the way a bee node does. Data chunks are
+// discarded, manifest nodes are kept in a throwaway store until the manifest is saved. The
+// defaults of the wrapped client do not apply.
+type reference struct {
+	o       *blockstore.UploadOptions
+	encrypt bool
+	level   redundancy.Level
+}
+
+func newReference(opts []blockstore.UploadOption) *reference {
+	r := &reference{o: blockstore.NewUploadOptions(opts...)}
+	if r.o.Encrypt != nil {
+		r.encrypt = *r.o.Encrypt
+	}
+	if r.o.RedundancyLevel != nil {
+		r.level = *r.o.RedundancyLevel
+	}
+	return r
+}
+
+// done reports a dropped upload as a success
+func (r *reference) done(ref swarm.Address) (swarm.Address, error) {
+	if r.o.Result != nil {
+		*r.o.Result = blockstore.UploadResult{Tag: r.o.Tag}
+	}
+	return ref, nil
+}
+
+// soc returns the address of the single owner chunk of owner with id, once its signature is checked
+func (r *reference) soc(owner, id, signature string, data []byte) (swarm.Address, error) {
+	var parts [3][]byte
+	for i, s := range []string{owner, id, signature} {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+		}
+		parts[i] = b
+	}
+	ch, err := cac.NewWithDataSpan(data)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	s, err := soc.NewSigned(parts[1], ch, parts[0], parts[2])
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+	}
+	sch, err := s.Chunk()
+	if err != nil || !soc.Valid(sch) {
+		return swarm.ZeroAddress, blockstore.ErrInvalidSOC
+	}
+	return r.done(sch.Address())
+}
+
+// blob returns the root reference of data split like the /bytes endpoint does
+func (r *reference) blob(data io.Reader) (swarm.Address, error) {
+	ref, err := r.split(data)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return r.done(ref)
+}
+
+// file returns the reference of the manifest the /bzz endpoint builds for a single file, changed
+// like bee.Client changes it
+func (r *reference) file(data []byte, fileName string) (swarm.Address, error) {
+	if err := r.o.Bzz.Validate([]string{fileName}); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ctx := context.Background()
+	ref, err := r.split(bytes.NewReader(data))
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ls := r.loadSaver()
+	m, err := manifest.NewDefaultManifest(ls, r.encrypt)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	rootMetadata := map[string]string{manifest.WebsiteIndexDocumentSuffixKey: fileName}
+	if err = m.Add(ctx, manifest.RootPath, manifest.NewEntry(swarm.ZeroAddress, rootMetadata)); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	fileMetadata := map[string]string{
+		manifest.EntryMetadataContentTypeKey: fileContentType,
+		manifest.EntryMetadataFilenameKey:    fileName,
+	}
+	if err = m.Add(ctx, fileName, manifest.NewEntry(ref, fileMetadata)); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if ref, err = m.Store(ctx); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if r.o.Bzz.ErrorDocument == "" && len(r.o.Bzz.Metadata) == 0 {
+		return r.done(ref)
+	}
+	b, err := bsmanifest.LoadBuilder(ls, ref)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return r.save(ctx, b)
+}
+
+// bzz returns the reference of the manifest of the files of the tar stream
+func (r *reference) bzz(data *bstar.Stream) (swarm.Address, error) {
+	if err := r.o.Bzz.Validate(data.Paths()); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ctx := context.Background()
+	b, err := bsmanifest.NewBuilder(r.loadSaver(), r.encrypt)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	tr := tar.NewReader(bytes.NewReader(data.Output().Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		ref, err := r.split(tr)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if err = b.AddFile(ctx, hdr.Name, ref, mime.TypeByExtension(path.Ext(hdr.Name))); err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+	b.SetIndexDocument(r.o.Bzz.IndexDocument)
+	return r.save(ctx, b)
+}
+
+// feedManifest returns the reference of the manifest of the sequence feed of owner and topic
+func (r *reference) feedManifest(owner, topic string) (swarm.Address, error) {
+	if !common.IsHexAddress(owner) {
+		return swarm.ZeroAddress, fmt.Errorf("invalid feed owner %q", owner)
+	}
+	topicBytes, err := hex.DecodeString(topic)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("invalid feed topic %q: %w", topic, err)
+	}
+	ctx := context.Background()
+	m, err := manifest.NewDefaultManifest(r.loadSaver(), false)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	metadata := map[string]string{
+		feedMetadataEntryOwner: hex.EncodeToString(common.HexToAddress(owner).Bytes()),
+		feedMetadataEntryTopic: hex.EncodeToString(topicBytes),
+		feedMetadataEntryType:  feeds.Sequence.String(),
+	}
+	if err = m.Add(ctx, manifest.RootPath, manifest.NewEntry(swarm.NewAddress(make([]byte, swarm.HashSize)), metadata)); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ref, err := m.Store(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return r.done(ref)
+}
+
+// save adds the documents and metadata of the upload to the manifest and saves it
+func (r *reference) save(ctx context.Context, b *bsmanifest.Builder) (swarm.Address, error) {
+	b.SetErrorDocument(r.o.Bzz.ErrorDocument)
+	b.SetRootMetadata(r.o.Bzz.Metadata)
+	ref, err := b.Save(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return r.done(ref)
+}
+
+// split splits data with the pipeline of bee and discards the chunks
+func (r *reference) split(data io.Reader) (swarm.Address, error) {
+	ctx := context.Background()
+	return builder.FeedPipeline(ctx, builder.NewPipelineBuilder(ctx, discard, r.encrypt, r.level), data)
+}
+
+// loadSaver keeps manifest nodes in a throwaway store, the builders load them back
+func (r *reference) loadSaver() file.LoadSaver {
+	store := inmemchunkstore.New()
+	return loadsave.New(store, store, func() pipeline.Interface {
+		return builder.NewPipelineBuilder(context.Background(), store, r.encrypt, r.level)
+	})
+}
@@ -0,0 +1,152 @@
+package swarm_feed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/putergetter"
+	"github.com/ethersphere/bee/v2/pkg/bmt"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	timestampSize = 8
+	// maxDirectDataSize is the largest payload that fits into a single update chunk next to the timestamp
+	maxDirectDataSize = swarm.ChunkSize - timestampSize
+)
+
+var errRootChunkNotFound = errors.New("root chunk of wrapped payload not found")
+
+// UploadData writes arbitrary data as the next update of a feed. Like WriteUpdate it returns the
+// address of the update, not of the feed manifest, which Manifest returns.
+// Data up to maxDirectDataSize bytes is stored directly in the update. Larger data is split into a
+// content addressed chunk tree, and the update wraps the root chunk of that tree.
+// The owner may be left empty, in which case it is derived from the signer.
+func (f *Feed) UploadData(owner, topic string, signer crypto.Signer, data []byte, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	ctx := context.Background()
+	owner, err := resolveOwner(owner, signer)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ownerBytes, err := parseOwner(owner)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	topicHash := f.topicHash(topic)
+
+	nextIndex, err := f.nextIndex(ctx, ownerBytes, topicHash)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	id, err := makeSequentialFeedIdentifier(topicHash, int64(nextIndex))
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	ch, err := f.dataChunk(ctx, topicHash, nextIndex, data, opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return f.uploadUpdate(ctx, id, ch, signer, opts)
+}
+
+// DownloadData returns the latest update of a feed written by UploadData, with the data
+// of wrapped chunk trees already joined.
+func (f *Feed) DownloadData(ctx context.Context, owner, topic string) (*Update, error) {
+	ownerBytes, err := parseOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	topicHash := f.topicHash(topic)
+	latest, err := f.latestIndex(ctx, ownerBytes, topicHash)
+	if err != nil {
+		return nil, err
+	}
+	return f.getUpdate(ctx, ownerBytes, topicHash, latest)
+}
+
+// dataChunk builds the chunk wrapped by the data update at index, uploading the chunk tree of large data
+func (f *Feed) dataChunk(ctx context.Context, topic Identifier, index uint64, data []byte, opts []blockstore.UploadOption) (swarm.Chunk, error) {
+	data, err := f.encrypt(topic, index, data)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := numberToUint64BE(time.Now().Unix())
+	content := concatBytes(timestamp, data)
+	if len(data) <= maxDirectDataSize {
+		return cac.New(content)
+	}
+	return f.splitContent(ctx, content, opts)
+}
+
+// splitContent uploads content as a chunk tree and returns its root chunk. The tree is erasure
+// coded with the redundancy level of opts, the chunks are split here so the default of the
+// client does not apply.
+func (f *Feed) splitContent(ctx context.Context, content []byte, opts []blockstore.UploadOption) (swarm.Chunk, error) {
+	rLevel := redundancy.NONE
+	if level := blockstore.NewUploadOptions(opts...).RedundancyLevel; level != nil {
+		rLevel = *level
+	}
+	pg, err := putergetter.NewPutGetter(f.bClient, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the root chunk is the last one stored by the pipeline
+	var last swarm.Chunk
+	putter := storage.PutterFunc(func(ctx context.Context, ch swarm.Chunk) error {
+		last = ch
+		return pg.Put(ctx, ch)
+	})
+	root, err := builder.FeedPipeline(ctx, builder.NewPipelineBuilder(ctx, putter, false, rLevel), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if last == nil || !last.Address().Equal(root) {
+		return nil, errRootChunkNotFound
+	}
+	return last, nil
+}
+
+// updateContent returns the timestamp prefixed content of an update, joining
+// the chunk tree if the update wraps the root of one
+func (f *Feed) updateContent(ctx context.Context, ch swarm.Chunk) ([]byte, error) {
+	s, err := soc.FromChunk(ch)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := s.WrappedChunk()
+	if len(wrapped.Data()) < swarm.SpanSize {
+		return nil, errInvalidUpdate
+	}
+	if bmt.LengthFromSpan(wrapped.Data()[:swarm.SpanSize]) <= swarm.ChunkSize {
+		return wrapped.Data()[swarm.SpanSize:], nil
+	}
+
+	getter := storage.GetterFunc(func(ctx context.Context, address swarm.Address) (swarm.Chunk, error) {
+		if address.Equal(wrapped.Address()) {
+			return wrapped, nil
+		}
+		return f.bClient.DownloadChunk(ctx, address)
+	})
+	j, span, err := joiner.New(ctx, getter, nil, wrapped.Address())
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, span)
+	if _, err = j.ReadAt(content, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return content, nil
+}
@@ -0,0 +1,89 @@
+package swarm_feed
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/encryption"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	errInvalidKeyLength      = errors.New("feed encryption key must be 32 bytes")
+	errHiddenTopicWithoutKey = errors.New("feed topic can only be hidden with an encryption key")
+
+	sharedKeySalt = []byte("swarm-feed")
+)
+
+type feedEncryption struct {
+	key       []byte
+	hideTopic bool
+}
+
+// WithEncryptionKey encrypts the payload of every update with a 32 byte symmetric key.
+// Only readers configured with the same key can decrypt the payloads.
+func WithEncryptionKey(key []byte) Option {
+	return func(f *Feed) {
+		if f.encryption == nil {
+			f.encryption = &feedEncryption{}
+		}
+		f.encryption.key = key
+	}
+}
+
+// WithHiddenTopic derives topic hashes from the encryption key as well, so the
+// updates of a feed can not be found by someone who only knows the owner and topic.
+// It requires WithEncryptionKey.
+func WithHiddenTopic() Option {
+	return func(f *Feed) {
+		if f.encryption == nil {
+			f.encryption = &feedEncryption{}
+		}
+		f.encryption.hideTopic = true
+	}
+}
+
+// SharedKey derives a feed encryption key from an ECDH exchange. The writer uses its
+// private key and the public key of the reader, the reader does the opposite.
+func SharedKey(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	return crypto.NewDH(privateKey).SharedKey(publicKey, sharedKeySalt)
+}
+
+// topicHash returns the feed topic of a plain topic string
+func (f *Feed) topicHash(topic string) Identifier {
+	if f.encryption != nil && f.encryption.hideTopic {
+		return keccak256Hash(f.encryption.key, []byte(topic))
+	}
+	return keccak256Hash([]byte(topic))
+}
+
+// encrypt encrypts the payload of the update at index, it is a no-op for unencrypted feeds
+func (f *Feed) encrypt(topic Identifier, index uint64, payload []byte) ([]byte, error) {
+	e, err := f.updateEncryption(topic, index)
+	if err != nil || e == nil {
+		return payload, err
+	}
+	return e.Encrypt(payload)
+}
+
+// decrypt decrypts the payload of the update at index, it is a no-op for unencrypted feeds
+func (f *Feed) decrypt(topic Identifier, index uint64, payload []byte) ([]byte, error) {
+	e, err := f.updateEncryption(topic, index)
+	if err != nil || e == nil {
+		return payload, err
+	}
+	return e.Decrypt(payload)
+}
+
+// updateEncryption derives a key for every update, so no two updates share a key stream
+func (f *Feed) updateEncryption(topic Identifier, index uint64) (encryption.Interface, error) {
+	if f.encryption == nil || f.encryption.key == nil {
+		return nil, nil
+	}
+	if len(f.encryption.key) != encryption.KeyLength {
+		return nil, errInvalidKeyLength
+	}
+	key := keccak256Hash(f.encryption.key, topic, numberToUint64BE(int64(index)))
+	return encryption.New(encryption.Key(key), 0, 0, sha3.NewLegacyKeccak256), nil
+}
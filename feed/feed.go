@@ -2,18 +2,17 @@ package swarm_feed
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
 	blockstore "github.com/asabya/swarm-blockstore"
 	"github.com/ethersphere/bee/v2/pkg/cac"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
-	"github.com/ethersphere/bee/v2/pkg/soc"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"golang.org/x/crypto/sha3"
 )
@@ -29,41 +28,98 @@ type Identifier []byte
 type IndexBytes []byte
 
 type Feed struct {
-	bClient blockstore.Client
+	bClient    blockstore.Client
+	manifests  ManifestStore
+	encryption *feedEncryption
 }
 
-func NewFeed(bClient blockstore.Client) *Feed {
-	return &Feed{bClient: bClient}
+// Option configures a Feed
+type Option func(f *Feed)
+
+// WithManifestStore sets the store used to cache feed manifests
+func WithManifestStore(store ManifestStore) Option {
+	return func(f *Feed) {
+		f.manifests = store
+	}
+}
+
+// NewFeed returns a Feed that reads and writes updates through bClient. It panics when
+// WithHiddenTopic is given without WithEncryptionKey, as the topic would not be hidden.
+func NewFeed(bClient blockstore.Client, opts ...Option) *Feed {
+	f := &Feed{
+		bClient:   bClient,
+		manifests: NewMemoryManifestStore(),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.encryption != nil && f.encryption.hideTopic && f.encryption.key == nil {
+		panic(errHiddenTopicWithoutKey)
+	}
+	return f
 }
 
-func (f *Feed) Upload(owner, topic, stamp, redundancyLevel string, pin bool, signer crypto.Signer, payload swarm.Address) (swarm.Address, error) {
-	topicHash := keccak256Hash([]byte(topic))
-	_, _, nextIndex, _ := f.bClient.GetLatestFeedManifest(owner, Encode(topicHash))
-	if nextIndex == "" {
-		nextIndex = strings.Repeat("0", FEED_INDEX_HEX_LENGTH)
+// Upload writes payload as the next update of a feed and returns the address of the feed manifest,
+// creating the manifest if needed. WriteUpdate and UploadData return the address of the update instead.
+// The owner may be left empty, in which case it is derived from the signer.
+func (f *Feed) Upload(owner, topic string, signer crypto.Signer, payload swarm.Address, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	owner, err := resolveOwner(owner, signer)
+	if err != nil {
+		return swarm.ZeroAddress, err
 	}
-	id, err := makeFeedIdentifier(topicHash, nextIndex)
+	_, _, err = f.WriteUpdate(owner, topic, signer, payload, opts...)
 	if err != nil {
 		return swarm.ZeroAddress, err
 	}
+	return f.Manifest(owner, topic, opts...)
+}
 
-	timestamp := numberToUint64BE(time.Now().Unix())
-	payloadBytes := concatBytes(timestamp, payload.Bytes())
+// WriteUpdate writes payload as the next update of a feed without touching the feed manifest.
+// It returns the address and the index of the update.
+func (f *Feed) WriteUpdate(owner, topic string, signer crypto.Signer, payload swarm.Address, opts ...blockstore.UploadOption) (swarm.Address, uint64, error) {
+	ctx := context.Background()
+	owner, err := resolveOwner(owner, signer)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	ownerBytes, err := parseOwner(owner)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	topicHash := f.topicHash(topic)
+	index, err := f.nextIndex(ctx, ownerBytes, topicHash)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	id, err := makeSequentialFeedIdentifier(topicHash, int64(index))
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
 
-	ch, err := cac.New(payloadBytes)
+	ch, err := f.referenceChunk(topicHash, index, payload)
 	if err != nil {
-		return swarm.ZeroAddress, err
+		return swarm.ZeroAddress, 0, err
 	}
-	s := soc.New(soc.ID(id), ch)
-	_, err = s.Sign(signer)
+	addr, err := f.uploadUpdate(ctx, id, ch, signer, opts)
 	if err != nil {
-		return swarm.ZeroAddress, err
+		return swarm.ZeroAddress, 0, err
 	}
-	_, err = f.bClient.UploadSOC(owner, Encode(id), Encode(s.Signature()), stamp, redundancyLevel, pin, ch.Data())
+	return addr, index, nil
+}
+
+// referenceChunk builds the chunk wrapped by the update at index pointing at payload
+func (f *Feed) referenceChunk(topic Identifier, index uint64, payload swarm.Address) (swarm.Chunk, error) {
+	ref, err := f.encrypt(topic, index, payload.Bytes())
 	if err != nil {
-		return swarm.ZeroAddress, err
+		return nil, err
 	}
-	return f.bClient.CreateFeedManifest(owner, Encode(topicHash), stamp, pin)
+	timestamp := numberToUint64BE(time.Now().Unix())
+	return cac.New(concatBytes(timestamp, ref))
+}
+
+// uploadUpdate signs the chunk as a single owner chunk with the given id and uploads it
+func (f *Feed) uploadUpdate(ctx context.Context, id Identifier, ch swarm.Chunk, signer crypto.Signer, opts []blockstore.UploadOption) (swarm.Address, error) {
+	return blockstore.WriteSOCChunk(ctx, f.bClient, signer, id, ch, opts...)
 }
 
 func concatBytes(byteSlices ...[]byte) []byte {
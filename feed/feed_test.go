@@ -1,26 +1,519 @@
 package swarm_feed_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	blockstore "github.com/asabya/swarm-blockstore"
 	"github.com/asabya/swarm-blockstore/bee"
 	"github.com/asabya/swarm-blockstore/bee/mock"
-	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
-	mockpost "github.com/ethersphere/bee/v2/pkg/postage/mock"
-	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+	swarm_feed "github.com/asabya/swarm-blockstore/feed"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
 func TestFeed(t *testing.T) {
 	t.Skip()
-	storer := mockstorer.New()
-	beeUrl := mock.NewTestBeeServer(t, mock.TestServerOptions{
-		Storer:          storer,
-		PreventRedirect: true,
-		Post:            mockpost.New(mockpost.WithAcceptAll()),
-		Feeds:           factory.New(storer.Lookup()),
-	})
-	mockClient := bee.NewBeeClient(beeUrl, bee.WithStamp(mock.BatchOkStr), bee.WithRedundancy("0"))
+	mockClient := bee.NewBeeClient(mock.NewTestServer(t, mock.TestServerOptions{}), bee.WithStamp(mock.BatchOkStr), bee.WithRedundancy(redundancy.NONE))
 	_ = mockClient
 
 	// TODO test
 }
+
+func newTestClient(t *testing.T) *bee.Client {
+	t.Helper()
+	return mock.NewTestClient(t, mock.TestServerOptions{})
+}
+
+func newTestSigner(t *testing.T) (crypto.Signer, string) {
+	t.Helper()
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(pk)
+	ethAddr, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, swarm_feed.Encode(ethAddr.Bytes())
+}
+
+func newTestFeed(t *testing.T) (*swarm_feed.Feed, crypto.Signer, string) {
+	t.Helper()
+	signer, owner := newTestSigner(t)
+	return swarm_feed.NewFeed(newTestClient(t)), signer, owner
+}
+
+func TestFeedHistory(t *testing.T) {
+	f, signer, owner := newTestFeed(t)
+	ctx := context.Background()
+
+	it := f.History(ctx, owner, "history")
+	if it.Next() {
+		t.Fatal("expected empty history")
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+
+	refs := make([]swarm.Address, 5)
+	for i := range refs {
+		refs[i] = swarm.RandAddress(t)
+		_, err := f.Upload(owner, "history", signer, refs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		it := f.History(ctx, owner, "history", swarm_feed.WithWindow(2))
+		var i uint64
+		for ; it.Next(); i++ {
+			u := it.Update()
+			if u.Index != i {
+				t.Fatalf("expected index %d, got %d", i, u.Index)
+			}
+			if !bytes.Equal(u.Payload, refs[i].Bytes()) {
+				t.Fatalf("payload mismatch at index %d", i)
+			}
+		}
+		if it.Err() != nil {
+			t.Fatal(it.Err())
+		}
+		if i != uint64(len(refs)) {
+			t.Fatalf("expected %d updates, got %d", len(refs), i)
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		it := f.History(ctx, owner, "history", swarm_feed.WithReverse(), swarm_feed.WithWindow(3))
+		i := len(refs) - 1
+		for ; it.Next(); i-- {
+			u := it.Update()
+			if u.Index != uint64(i) {
+				t.Fatalf("expected index %d, got %d", i, u.Index)
+			}
+		}
+		if it.Err() != nil {
+			t.Fatal(it.Err())
+		}
+		if i != -1 {
+			t.Fatalf("history stopped at index %d", i)
+		}
+	})
+
+	t.Run("lookup at", func(t *testing.T) {
+		u, err := f.LookupAt(ctx, owner, "history", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Index != uint64(len(refs)-1) {
+			t.Fatalf("expected latest index %d, got %d", len(refs)-1, u.Index)
+		}
+		_, err = f.LookupAt(ctx, owner, "history", time.Now().Add(-time.Hour))
+		if !errors.Is(err, swarm_feed.ErrNoUpdate) {
+			t.Fatalf("expected ErrNoUpdate, got %v", err)
+		}
+	})
+}
+
+func TestFeedData(t *testing.T) {
+	f, signer, owner := newTestFeed(t)
+	ctx := context.Background()
+
+	_, err := f.DownloadData(ctx, owner, "data")
+	if !errors.Is(err, swarm_feed.ErrNoUpdate) {
+		t.Fatalf("expected ErrNoUpdate, got %v", err)
+	}
+
+	large := make([]byte, 3*swarm.ChunkSize+100)
+	_, _ = rand.Read(large)
+	payloads := [][]byte{
+		[]byte(`{"status":"ok"}`),
+		large,
+		make([]byte, swarm.ChunkSize-8),
+	}
+	for i, payload := range payloads {
+		_, err := f.UploadData(owner, "data", signer, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := f.DownloadData(ctx, owner, "data")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Index != uint64(i) {
+			t.Fatalf("expected index %d, got %d", i, u.Index)
+		}
+		if !bytes.Equal(u.Payload, payload) {
+			t.Fatalf("payload mismatch at index %d", i)
+		}
+	}
+
+	// reference updates continue after data updates the node cannot parse
+	_, index, err := f.WriteUpdate(owner, "data", signer, swarm.RandAddress(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != uint64(len(payloads)) {
+		t.Fatalf("expected index %d, got %d", len(payloads), index)
+	}
+}
+
+func TestFeedUploadOptions(t *testing.T) {
+	// an unpinned update is pushed by the node
+	client := mock.NewTestClient(t, mock.TestServerOptions{DirectUpload: true})
+	f := swarm_feed.NewFeed(client)
+	signer, owner := newTestSigner(t)
+
+	// the pinning default of the client applies unless the call overrides it
+	pinned, err := f.UploadData(owner, "options", signer, []byte("pinned"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpinned, err := f.UploadData(owner, "options", signer, []byte("unpinned"), blockstore.WithPin(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ref, expected := range map[string]bool{pinned.String(): true, unpinned.String(): false} {
+		addr := swarm.MustParseHexAddress(ref)
+		ok, err := client.IsPinned(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Fatalf("expected pinned %v for %s, got %v", expected, ref, ok)
+		}
+	}
+}
+
+func TestFeedOwner(t *testing.T) {
+	f, signer, owner := newTestFeed(t)
+	ctx := context.Background()
+
+	derived, err := swarm_feed.OwnerFromSigner(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if derived != owner {
+		t.Fatalf("expected owner %s, got %s", owner, derived)
+	}
+
+	_, err = f.UploadBySigner("owner", signer, swarm.RandAddress(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Upload("0x"+strings.ToUpper(owner), "owner", signer, swarm.RandAddress(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := f.LookupAt(ctx, owner, "owner", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Index != 1 {
+		t.Fatalf("expected index 1, got %d", u.Index)
+	}
+
+	other := swarm_feed.Encode(swarm.RandAddress(t).Bytes()[:20])
+	_, err = f.Upload(other, "owner", signer, swarm.RandAddress(t))
+	if !errors.Is(err, swarm_feed.ErrOwnerMismatch) {
+		t.Fatalf("expected ErrOwnerMismatch, got %v", err)
+	}
+	_, err = f.UploadData(other, "owner", signer, []byte("data"))
+	if !errors.Is(err, swarm_feed.ErrOwnerMismatch) {
+		t.Fatalf("expected ErrOwnerMismatch, got %v", err)
+	}
+}
+
+type manifestCountingClient struct {
+	blockstore.Client
+	created int
+}
+
+func (c *manifestCountingClient) CreateFeedManifest(owner, topic string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	c.created++
+	return c.Client.CreateFeedManifest(owner, topic, opts...)
+}
+
+func TestFeedManifestCache(t *testing.T) {
+	client := &manifestCountingClient{Client: newTestClient(t)}
+	signer, owner := newTestSigner(t)
+	f := swarm_feed.NewFeed(client)
+
+	var manifest swarm.Address
+	for i := 0; i < 3; i++ {
+		ref, err := f.Upload(owner, "manifest", signer, swarm.RandAddress(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && !ref.Equal(manifest) {
+			t.Fatalf("manifest changed from %s to %s", manifest, ref)
+		}
+		manifest = ref
+	}
+	if client.created != 1 {
+		t.Fatalf("expected manifest to be created once, got %d", client.created)
+	}
+
+	addr, index, err := f.WriteUpdate(owner, "manifest", signer, swarm.RandAddress(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 3 {
+		t.Fatalf("expected index 3, got %d", index)
+	}
+	ch, err := client.DownloadChunk(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ch.Address().Equal(addr) {
+		t.Fatalf("expected update at %s", addr)
+	}
+	if client.created != 1 {
+		t.Fatalf("expected no manifest from WriteUpdate, got %d", client.created)
+	}
+}
+
+func TestFeedWriter(t *testing.T) {
+	f, signer, owner := newTestFeed(t)
+	ctx := context.Background()
+	store := swarm_feed.NewFileIndexStore(filepath.Join(t.TempDir(), "indexes.json"))
+
+	w, err := f.NewWriter("writer", signer, swarm_feed.WithIndexStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Owner() != owner {
+		t.Fatalf("expected owner %s, got %s", owner, w.Owner())
+	}
+
+	const writes = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, writes)
+	for i := 0; i < writes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := w.Write(ctx, swarm.RandAddress(t))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	u, err := f.LookupAt(ctx, owner, "writer", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Index != writes-1 {
+		t.Fatalf("expected latest index %d, got %d", writes-1, u.Index)
+	}
+
+	t.Run("restart", func(t *testing.T) {
+		next, err := store.GetIndex(owner, "writer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != writes {
+			t.Fatalf("expected stored index %d, got %d", writes, next)
+		}
+		// the stored index is used as is, even if it is ahead of the network
+		if err = store.PutIndex(owner, "writer", writes+10); err != nil {
+			t.Fatal(err)
+		}
+		restarted, err := f.NewWriter("writer", signer, swarm_feed.WithIndexStore(store))
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err = restarted.NextIndex(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != writes+10 {
+			t.Fatalf("expected index %d, got %d", writes+10, next)
+		}
+		if err = restarted.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+		next, err = restarted.NextIndex(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != writes {
+			t.Fatalf("expected synced index %d, got %d", writes, next)
+		}
+	})
+
+	t.Run("moved", func(t *testing.T) {
+		stale := swarm_feed.NewMemoryIndexStore()
+		if err := stale.PutIndex(owner, "writer", writes-2); err != nil {
+			t.Fatal(err)
+		}
+		other, err := f.NewWriter("writer", signer, swarm_feed.WithIndexStore(stale))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = other.WriteData(ctx, []byte("late"))
+		if !errors.Is(err, swarm_feed.ErrFeedMoved) {
+			t.Fatalf("expected ErrFeedMoved, got %v", err)
+		}
+		_, index, err := other.WriteData(ctx, []byte("retry"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != writes {
+			t.Fatalf("expected index %d, got %d", writes, index)
+		}
+	})
+}
+
+func TestFeedEncryption(t *testing.T) {
+	client := newTestClient(t)
+	signer, owner := newTestSigner(t)
+	ctx := context.Background()
+
+	writerKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readerKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := swarm_feed.SharedKey(writerKey, &readerKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readerShared, err := swarm_feed.SharedKey(readerKey, &writerKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, readerShared) {
+		t.Fatal("shared keys differ")
+	}
+
+	plain := swarm_feed.NewFeed(client)
+	private := swarm_feed.NewFeed(client, swarm_feed.WithEncryptionKey(key))
+	reader := swarm_feed.NewFeed(client, swarm_feed.WithEncryptionKey(readerShared))
+
+	data := []byte("private update")
+	if _, err = private.UploadData(owner, "private", signer, data); err != nil {
+		t.Fatal(err)
+	}
+	u, err := reader.DownloadData(ctx, owner, "private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(u.Payload, data) {
+		t.Fatal("reader with key could not decrypt the update")
+	}
+	u, err = plain.DownloadData(ctx, owner, "private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(u.Payload, data) {
+		t.Fatal("update is readable without the key")
+	}
+
+	hidden := swarm_feed.NewFeed(client, swarm_feed.WithEncryptionKey(key), swarm_feed.WithHiddenTopic())
+	if _, err = hidden.UploadData(owner, "hidden", signer, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = private.DownloadData(ctx, owner, "hidden"); !errors.Is(err, swarm_feed.ErrNoUpdate) {
+		t.Fatalf("expected ErrNoUpdate without the hidden topic, got %v", err)
+	}
+	u, err = hidden.DownloadData(ctx, owner, "hidden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(u.Payload, data) {
+		t.Fatal("hidden topic update mismatch")
+	}
+
+	bad := swarm_feed.NewFeed(client, swarm_feed.WithEncryptionKey([]byte("short")))
+	if _, err = bad.UploadData(owner, "bad", signer, data); err == nil {
+		t.Fatal("expected error for invalid key length")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a hidden topic without a key to panic")
+			}
+		}()
+		swarm_feed.NewFeed(client, swarm_feed.WithHiddenTopic())
+	}()
+}
+
+func TestFeedSubscribe(t *testing.T) {
+	f, signer, owner := newTestFeed(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := f.NewWriter("subscribe", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = w.WriteData(ctx, []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	notify := make(chan struct{})
+	updates, err := f.Subscribe(ctx, owner, "subscribe", swarm_feed.WithPollInterval(time.Hour, time.Hour), swarm_feed.WithNotifications(notify))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := f.Subscribe(ctx, owner, "subscribe", swarm_feed.WithPollInterval(10*time.Millisecond, 50*time.Millisecond), swarm_feed.WithStartIndex(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receive := func(updates <-chan *swarm_feed.Update, index uint64, payload string) {
+		t.Helper()
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				t.Fatal("subscription closed")
+			}
+			if u.Index != index || string(u.Payload) != payload {
+				t.Fatalf("expected update %d %q, got %d %q", index, payload, u.Index, u.Payload)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for update %d", index)
+		}
+	}
+
+	receive(replay, 0, "old")
+	for i, payload := range []string{"first", "second"} {
+		if _, _, err = w.WriteData(ctx, []byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+		notify <- struct{}{}
+		receive(updates, uint64(i+1), payload)
+		receive(replay, uint64(i+1), payload)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatal("unexpected update after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed after cancel")
+	}
+}
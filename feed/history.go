@@ -0,0 +1,310 @@
+package swarm_feed
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	defaultHistoryWindow = 8
+)
+
+var (
+	// ErrNoUpdate is returned when a feed has no update matching the lookup
+	ErrNoUpdate = errors.New("no feed update found")
+
+	errInvalidUpdate = errors.New("invalid feed update")
+)
+
+// Update is a single update of a sequential feed
+type Update struct {
+	Index     uint64
+	Timestamp time.Time
+	Payload   []byte
+}
+
+type historyOptions struct {
+	window  int
+	reverse bool
+}
+
+// HistoryOption configures a feed history Iterator
+type HistoryOption func(o *historyOptions)
+
+// WithWindow sets how many updates are fetched concurrently
+func WithWindow(window int) HistoryOption {
+	return func(o *historyOptions) {
+		if window > 0 {
+			o.window = window
+		}
+	}
+}
+
+// WithReverse walks the history backwards, starting from the latest update
+func WithReverse() HistoryOption {
+	return func(o *historyOptions) {
+		o.reverse = true
+	}
+}
+
+// Iterator walks the updates of a sequential feed in index order
+type Iterator struct {
+	ctx     context.Context
+	feed    *Feed
+	owner   []byte
+	topic   Identifier
+	opts    historyOptions
+	started bool
+	next    uint64
+	last    uint64
+	done    bool
+	buf     []*Update
+	current *Update
+	err     error
+}
+
+// History returns an Iterator over all updates of the feed, from index 0 by default
+func (f *Feed) History(ctx context.Context, owner, topic string, opts ...HistoryOption) *Iterator {
+	o := historyOptions{
+		window: defaultHistoryWindow,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	it := &Iterator{
+		ctx:   ctx,
+		feed:  f,
+		topic: f.topicHash(topic),
+		opts:  o,
+	}
+	it.owner, it.err = parseOwner(owner)
+	return it
+}
+
+// Next advances the iterator to the next update. It returns false when the
+// history is exhausted or an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		latest, err := it.feed.latestIndex(it.ctx, it.owner, it.topic)
+		if err != nil {
+			if !errors.Is(err, ErrNoUpdate) {
+				it.err = err
+			}
+			it.done = true
+			return false
+		}
+		it.last = latest
+		if it.opts.reverse {
+			it.next = latest
+		}
+	}
+	if len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		it.fill()
+		if it.err != nil {
+			return false
+		}
+	}
+	it.current, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Update returns the update the iterator currently points at
+func (it *Iterator) Update() *Update {
+	return it.current
+}
+
+// Err returns the first error encountered while iterating
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// fill fetches the next window of updates concurrently
+func (it *Iterator) fill() {
+	var indices []uint64
+	for len(indices) < it.opts.window && !it.done {
+		indices = append(indices, it.next)
+		switch {
+		case it.opts.reverse && it.next == 0:
+			it.done = true
+		case !it.opts.reverse && it.next == it.last:
+			it.done = true
+		case it.opts.reverse:
+			it.next--
+		default:
+			it.next++
+		}
+	}
+
+	updates := make([]*Update, len(indices))
+	errs := make([]error, len(indices))
+	var wg sync.WaitGroup
+	for i, index := range indices {
+		wg.Add(1)
+		go func(i int, index uint64) {
+			defer wg.Done()
+			updates[i], errs[i] = it.feed.getUpdate(it.ctx, it.owner, it.topic, index)
+		}(i, index)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			it.err = err
+			return
+		}
+	}
+	it.buf = updates
+}
+
+// LookupAt returns the latest update of a sequential feed that was made at or before t
+func (f *Feed) LookupAt(ctx context.Context, owner, topic string, t time.Time) (*Update, error) {
+	ownerBytes, err := parseOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	topicHash := f.topicHash(topic)
+	latest, err := f.latestIndex(ctx, ownerBytes, topicHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// binary search for the last index with a timestamp not after t
+	var found *Update
+	lo, hi := uint64(0), latest+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		u, err := f.getUpdate(ctx, ownerBytes, topicHash, mid)
+		if err != nil {
+			return nil, err
+		}
+		if u.Timestamp.After(t) {
+			hi = mid
+		} else {
+			found = u
+			lo = mid + 1
+		}
+	}
+	if found == nil {
+		return nil, ErrNoUpdate
+	}
+	return found, nil
+}
+
+// latestIndex finds the index of the latest update by probing exponentially
+// growing indices and then bisecting between the last hit and the first miss
+func (f *Feed) latestIndex(ctx context.Context, owner []byte, topic Identifier) (uint64, error) {
+	ok, err := f.hasUpdate(ctx, owner, topic, 0)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrNoUpdate
+	}
+
+	lo, hi := uint64(0), uint64(1)
+	for {
+		ok, err = f.hasUpdate(ctx, owner, topic, hi)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		lo, hi = hi, hi*2
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err = f.hasUpdate(ctx, owner, topic, mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// nextIndex returns the index the next update of the feed is written at. It probes the update
+// chunks like latestIndex, so it finds updates of any kind, not only the reference updates that
+// GetLatestFeedManifest can parse.
+func (f *Feed) nextIndex(ctx context.Context, owner []byte, topic Identifier) (uint64, error) {
+	latest, err := f.latestIndex(ctx, owner, topic)
+	switch {
+	case err == nil:
+		return latest + 1, nil
+	case errors.Is(err, ErrNoUpdate):
+		return 0, nil
+	default:
+		return 0, err
+	}
+}
+
+func (f *Feed) hasUpdate(ctx context.Context, owner []byte, topic Identifier, index uint64) (bool, error) {
+	_, err := f.getChunk(ctx, owner, topic, index)
+	if errors.Is(err, blockstore.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (f *Feed) getChunk(ctx context.Context, owner []byte, topic Identifier, index uint64) (swarm.Chunk, error) {
+	id, err := makeSequentialFeedIdentifier(topic, int64(index))
+	if err != nil {
+		return nil, err
+	}
+	addr, err := soc.CreateAddress(soc.ID(id), owner)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := f.bClient.DownloadChunk(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !soc.Valid(ch) {
+		return nil, errInvalidUpdate
+	}
+	return ch, nil
+}
+
+func (f *Feed) getUpdate(ctx context.Context, owner []byte, topic Identifier, index uint64) (*Update, error) {
+	ch, err := f.getChunk(ctx, owner, topic, index)
+	if err != nil {
+		return nil, err
+	}
+	content, err := f.updateContent(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < timestampSize {
+		return nil, errInvalidUpdate
+	}
+	payload, err := f.decrypt(topic, index, content[timestampSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &Update{
+		Index:     index,
+		Timestamp: time.Unix(int64(binary.BigEndian.Uint64(content[:timestampSize])), 0),
+		Payload:   payload,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package swarm_feed
+
+import (
+	"errors"
+	"sync"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// ManifestStore keeps the feed manifest reference of an owner and topic, so
+// the manifest is only created once. Get returns blockstore.ErrNotFound for
+// unknown feeds.
+type ManifestStore interface {
+	Get(owner, topic string) (swarm.Address, error)
+	Put(owner, topic string, reference swarm.Address) error
+}
+
+type memoryManifestStore struct {
+	mtx       sync.RWMutex
+	manifests map[string]swarm.Address
+}
+
+// NewMemoryManifestStore returns a ManifestStore that keeps manifests in memory
+func NewMemoryManifestStore() ManifestStore {
+	return &memoryManifestStore{
+		manifests: make(map[string]swarm.Address),
+	}
+}
+
+func (m *memoryManifestStore) Get(owner, topic string) (swarm.Address, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	ref, ok := m.manifests[owner+"/"+topic]
+	if !ok {
+		return swarm.ZeroAddress, blockstore.ErrNotFound
+	}
+	return ref, nil
+}
+
+func (m *memoryManifestStore) Put(owner, topic string, reference swarm.Address) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.manifests[owner+"/"+topic] = reference
+	return nil
+}
+
+// Manifest returns the feed manifest of the owner and topic, creating it with opts only
+// if it is not in the manifest store yet
+func (f *Feed) Manifest(owner, topic string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	owner, err := FormatOwner(owner)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	topicHex := Encode(f.topicHash(topic))
+
+	ref, err := f.manifests.Get(owner, topicHex)
+	if err == nil {
+		return ref, nil
+	}
+	if !errors.Is(err, blockstore.ErrNotFound) {
+		return swarm.ZeroAddress, err
+	}
+
+	ref, err = f.bClient.CreateFeedManifest(owner, topicHex, opts...)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return ref, f.manifests.Put(owner, topicHex, ref)
+}
@@ -0,0 +1,85 @@
+package swarm_feed
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var (
+	// ErrOwnerMismatch is returned when an explicit owner is not the address of the signer
+	ErrOwnerMismatch = errors.New("owner does not match signer")
+
+	errInvalidOwner = errors.New("invalid owner address")
+)
+
+// OwnerFromSigner returns the ethereum address of the signer formatted the way
+// the /soc and /feeds endpoints expect it, lowercase hex without 0x prefix
+func OwnerFromSigner(signer crypto.Signer) (string, error) {
+	if signer == nil {
+		return "", errors.New("signer is required")
+	}
+	addr, err := signer.EthereumAddress()
+	if err != nil {
+		return "", err
+	}
+	return Encode(addr.Bytes()), nil
+}
+
+// FormatOwner normalizes an owner address to lowercase hex without 0x prefix
+func FormatOwner(owner string) (string, error) {
+	b, err := parseOwner(owner)
+	if err != nil {
+		return "", err
+	}
+	return Encode(b), nil
+}
+
+// UploadBySigner is Upload with the owner derived from the signer
+func (f *Feed) UploadBySigner(topic string, signer crypto.Signer, payload swarm.Address, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	return f.Upload("", topic, signer, payload, opts...)
+}
+
+// UploadDataBySigner is UploadData with the owner derived from the signer
+func (f *Feed) UploadDataBySigner(topic string, signer crypto.Signer, data []byte, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	return f.UploadData("", topic, signer, data, opts...)
+}
+
+// resolveOwner derives the owner from the signer. An empty owner is
+// replaced by the derived one, any other owner has to match it.
+func resolveOwner(owner string, signer crypto.Signer) (string, error) {
+	derived, err := OwnerFromSigner(signer)
+	if err != nil {
+		return "", err
+	}
+	if owner == "" {
+		return derived, nil
+	}
+	formatted, err := FormatOwner(owner)
+	if err != nil {
+		return "", err
+	}
+	if formatted != derived {
+		return "", fmt.Errorf("%w: owner %s, signer %s", ErrOwnerMismatch, formatted, derived)
+	}
+	return derived, nil
+}
+
+func parseOwner(owner string) ([]byte, error) {
+	b, err := hexToBytes(strings.ToLower(strip0x(owner)))
+	if err != nil || len(b) != crypto.AddressSize {
+		return nil, errInvalidOwner
+	}
+	return b, nil
+}
+
+func strip0x(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package swarm_feed
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+)
+
+const (
+	defaultMinPollInterval = time.Second
+	defaultMaxPollInterval = time.Minute
+)
+
+type subscribeOptions struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+	start       uint64
+	hasStart    bool
+	notify      <-chan struct{}
+}
+
+// SubscribeOption configures a feed subscription
+type SubscribeOption func(o *subscribeOptions)
+
+// WithPollInterval sets the bounds of the polling interval. The interval is reset to min
+// after every update and grows towards max while the feed is idle or lookups fail.
+func WithPollInterval(min, max time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if min > 0 {
+			o.minInterval = min
+		}
+		if max >= o.minInterval {
+			o.maxInterval = max
+		}
+	}
+}
+
+// WithStartIndex delivers all updates from index on, instead of only the updates made
+// after subscribing
+func WithStartIndex(index uint64) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.start = index
+		o.hasStart = true
+	}
+}
+
+// WithNotifications polls the feed right away whenever notify receives a value, for
+// example on a PSS or GSOC message announcing a new update. Polling at the regular
+// interval continues, so missed notifications only delay an update.
+func WithNotifications(notify <-chan struct{}) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.notify = notify
+	}
+}
+
+type subscription struct {
+	feed  *Feed
+	owner []byte
+	topic Identifier
+	opts  subscribeOptions
+	next  uint64
+}
+
+// Subscribe returns a channel receiving every new update of the feed in index order.
+// The channel is closed when ctx is done.
+func (f *Feed) Subscribe(ctx context.Context, owner, topic string, opts ...SubscribeOption) (<-chan *Update, error) {
+	o := subscribeOptions{
+		minInterval: defaultMinPollInterval,
+		maxInterval: defaultMaxPollInterval,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ownerBytes, err := parseOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	s := &subscription{
+		feed:  f,
+		owner: ownerBytes,
+		topic: f.topicHash(topic),
+		opts:  o,
+		next:  o.start,
+	}
+	if !o.hasStart {
+		latest, err := f.latestIndex(ctx, s.owner, s.topic)
+		switch {
+		case err == nil:
+			s.next = latest + 1
+		case !errors.Is(err, ErrNoUpdate):
+			return nil, err
+		}
+	}
+	updates := make(chan *Update)
+	go s.run(ctx, updates)
+	return updates, nil
+}
+
+func (s *subscription) run(ctx context.Context, updates chan<- *Update) {
+	defer close(updates)
+
+	notify := s.opts.notify
+	interval := s.opts.minInterval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case _, ok := <-notify:
+			if !ok {
+				notify = nil
+				continue
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+
+		found, err := s.poll(ctx, updates)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			// back off faster on errors than on an idle feed
+			interval *= 2
+		case found:
+			interval = s.opts.minInterval
+		default:
+			interval += interval / 2
+		}
+		if interval > s.opts.maxInterval {
+			interval = s.opts.maxInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// poll delivers all updates from the next expected index on and reports whether there were any
+func (s *subscription) poll(ctx context.Context, updates chan<- *Update) (bool, error) {
+	found := false
+	for {
+		u, err := s.feed.getUpdate(ctx, s.owner, s.topic, s.next)
+		if errors.Is(err, blockstore.ErrNotFound) {
+			return found, nil
+		}
+		if err != nil {
+			return found, err
+		}
+		select {
+		case updates <- u:
+		case <-ctx.Done():
+			return found, ctx.Err()
+		}
+		s.next++
+		found = true
+	}
+}
@@ -0,0 +1,289 @@
+package swarm_feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// ErrFeedMoved is returned by a FeedWriter when the index it was about to write
+// has already been written by another writer. The writer resyncs its index with
+// the network, so the write can be retried.
+var ErrFeedMoved = errors.New("feed was updated by another writer")
+
+// IndexStore persists the next index of a FeedWriter. GetIndex returns
+// blockstore.ErrNotFound for unknown feeds.
+type IndexStore interface {
+	GetIndex(owner, topic string) (uint64, error)
+	PutIndex(owner, topic string, next uint64) error
+}
+
+type memoryIndexStore struct {
+	mtx     sync.RWMutex
+	indexes map[string]uint64
+}
+
+// NewMemoryIndexStore returns an IndexStore that keeps indexes in memory
+func NewMemoryIndexStore() IndexStore {
+	return &memoryIndexStore{
+		indexes: make(map[string]uint64),
+	}
+}
+
+func (m *memoryIndexStore) GetIndex(owner, topic string) (uint64, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	next, ok := m.indexes[owner+"/"+topic]
+	if !ok {
+		return 0, blockstore.ErrNotFound
+	}
+	return next, nil
+}
+
+func (m *memoryIndexStore) PutIndex(owner, topic string, next uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.indexes[owner+"/"+topic] = next
+	return nil
+}
+
+type fileIndexStore struct {
+	mtx  sync.Mutex
+	path string
+}
+
+// NewFileIndexStore returns an IndexStore that keeps indexes in a json file at path,
+// so writers continue from the right index after a restart
+func NewFileIndexStore(path string) IndexStore {
+	return &fileIndexStore{path: path}
+}
+
+func (s *fileIndexStore) load() (map[string]uint64, error) {
+	indexes := make(map[string]uint64)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return indexes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+func (s *fileIndexStore) GetIndex(owner, topic string) (uint64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	indexes, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	next, ok := indexes[owner+"/"+topic]
+	if !ok {
+		return 0, blockstore.ErrNotFound
+	}
+	return next, nil
+}
+
+func (s *fileIndexStore) PutIndex(owner, topic string, next uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	indexes, err := s.load()
+	if err != nil {
+		return err
+	}
+	indexes[owner+"/"+topic] = next
+	data, err := json.Marshal(indexes)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a crash never leaves a truncated store
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+type writerOptions struct {
+	indexes IndexStore
+}
+
+// WriterOption configures a FeedWriter
+type WriterOption func(o *writerOptions)
+
+// WithIndexStore sets the store the FeedWriter saves its next index to
+func WithIndexStore(store IndexStore) WriterOption {
+	return func(o *writerOptions) {
+		o.indexes = store
+	}
+}
+
+// FeedWriter writes the updates of a single owner and topic. It keeps the next
+// index locally and serializes writes, so concurrent callers never race for
+// the same index.
+type FeedWriter struct {
+	mtx       sync.Mutex
+	feed      *Feed
+	owner     string
+	ownerKey  []byte
+	topic     string
+	topicHash Identifier
+	signer    crypto.Signer
+	indexes   IndexStore
+	next      uint64
+	loaded    bool
+}
+
+// NewWriter returns a FeedWriter for the topic, owned by the signer
+func (f *Feed) NewWriter(topic string, signer crypto.Signer, opts ...WriterOption) (*FeedWriter, error) {
+	o := writerOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.indexes == nil {
+		o.indexes = NewMemoryIndexStore()
+	}
+	owner, err := OwnerFromSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+	ownerKey, err := parseOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedWriter{
+		feed:      f,
+		owner:     owner,
+		ownerKey:  ownerKey,
+		topic:     topic,
+		topicHash: f.topicHash(topic),
+		signer:    signer,
+		indexes:   o.indexes,
+	}, nil
+}
+
+// Owner returns the owner of the feed
+func (w *FeedWriter) Owner() string {
+	return w.owner
+}
+
+// NextIndex returns the index the next update will be written at
+func (w *FeedWriter) NextIndex(ctx context.Context) (uint64, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err := w.load(ctx); err != nil {
+		return 0, err
+	}
+	return w.next, nil
+}
+
+// Write writes payload as the next reference update and returns the address and index of the update
+func (w *FeedWriter) Write(ctx context.Context, payload swarm.Address, opts ...blockstore.UploadOption) (swarm.Address, uint64, error) {
+	return w.write(ctx, opts, func(index uint64) (swarm.Chunk, error) {
+		return w.feed.referenceChunk(w.topicHash, index, payload)
+	})
+}
+
+// WriteData writes data as the next data update and returns the address and index of the update
+func (w *FeedWriter) WriteData(ctx context.Context, data []byte, opts ...blockstore.UploadOption) (swarm.Address, uint64, error) {
+	return w.write(ctx, opts, func(index uint64) (swarm.Chunk, error) {
+		return w.feed.dataChunk(ctx, w.topicHash, index, data, opts)
+	})
+}
+
+// Sync discards the local index and continues after the latest update found in the network
+func (w *FeedWriter) Sync(ctx context.Context) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.sync(ctx)
+}
+
+func (w *FeedWriter) write(ctx context.Context, opts []blockstore.UploadOption, build func(index uint64) (swarm.Chunk, error)) (swarm.Address, uint64, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if err := w.load(ctx); err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	index := w.next
+
+	exists, err := w.feed.hasUpdate(ctx, w.ownerKey, w.topicHash, index)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	if exists {
+		if err = w.sync(ctx); err != nil {
+			return swarm.ZeroAddress, 0, err
+		}
+		return swarm.ZeroAddress, 0, fmt.Errorf("%w: index %d is taken, next index is %d", ErrFeedMoved, index, w.next)
+	}
+
+	id, err := makeSequentialFeedIdentifier(w.topicHash, int64(index))
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	ch, err := build(index)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	addr, err := w.feed.uploadUpdate(ctx, id, ch, w.signer, opts)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+
+	w.next = index + 1
+	if err = w.indexes.PutIndex(w.owner, w.topic, w.next); err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	return addr, index, nil
+}
+
+// load reads the next index from the index store, falling back to the network
+func (w *FeedWriter) load(ctx context.Context) error {
+	if w.loaded {
+		return nil
+	}
+	next, err := w.indexes.GetIndex(w.owner, w.topic)
+	if errors.Is(err, blockstore.ErrNotFound) {
+		return w.sync(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	w.next = next
+	w.loaded = true
+	return nil
+}
+
+func (w *FeedWriter) sync(ctx context.Context) error {
+	next, err := w.feed.nextIndex(ctx, w.ownerKey, w.topicHash)
+	if err != nil {
+		return err
+	}
+	if err = w.indexes.PutIndex(w.owner, w.topic, next); err != nil {
+		return err
+	}
+	w.next = next
+	w.loaded = true
+	return nil
+}
@@ -0,0 +1,390 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/putergetter"
+	"github.com/ethersphere/bee/v2/pkg/encryption"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/manifest/mantaray"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// ErrInvalidPath is returned for paths that do not name an entry
+var ErrInvalidPath = errors.New("invalid manifest path")
+
+// NewLoadSaver loads manifest nodes through getter and stores them through putter,
+// for example a putergetter.PutGetter
+func NewLoadSaver(getter storage.Getter, putter storage.Putter) file.LoadSaver {
+	return loadsave.New(getter, putter, func() pipeline.Interface {
+		// manifest nodes fit in a chunk, so they are stored without parities
+		return builder.NewPipelineBuilder(context.Background(), putter, false, redundancy.NONE)
+	})
+}
+
+// NewClientLoadSaver loads manifest nodes with DownloadChunk and stores them with UploadChunk and opts
+func NewClientLoadSaver(c blockstore.ChunkStore, opts ...blockstore.UploadOption) (file.LoadSaver, error) {
+	pg, err := putergetter.NewPutGetter(c, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLoadSaver(pg, pg), nil
+}
+
+// Builder assembles a Mantaray manifest locally from references that are already
+// uploaded. Only the manifest nodes are stored when it is saved.
+type Builder struct {
+	m  manifest.Interface
+	ls file.LoadSaver
+	// refSize is the length of the references in the manifest
+	refSize int
+	// orig is the manifest the builder started from, it is only read
+	orig          manifest.Interface
+	origRoot      *mantaray.Node
+	indexDocument string
+	errorDocument string
+	rootMetadata  map[string]string
+	changes       map[string]*change
+	savedNodes    int
+}
+
+type snapshot struct {
+	exists   bool
+	ref      swarm.Address
+	metadata map[string]string
+}
+
+// change is the original and current entry of a changed path
+type change struct {
+	orig    *snapshot
+	current *snapshot
+}
+
+// Summary lists the paths changed by a Builder, compared to the manifest it started from
+type Summary struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+	// SavedNodes is the number of manifest nodes stored by the last Save
+	SavedNodes int
+}
+
+// NewBuilder returns a Builder for a new, empty manifest
+func NewBuilder(ls file.LoadSaver, encrypted bool) (*Builder, error) {
+	m, err := manifest.NewMantarayManifest(ls, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	refSize := swarm.HashSize
+	if encrypted {
+		refSize = encryption.ReferenceSize
+	}
+	return &Builder{m: m, ls: ls, refSize: refSize, changes: make(map[string]*change)}, nil
+}
+
+// LoadBuilder returns a Builder that changes the existing manifest at ref. Nodes are
+// loaded on demand and only the nodes on changed paths are stored again.
+func LoadBuilder(ls file.LoadSaver, ref swarm.Address) (*Builder, error) {
+	m, err := manifest.NewMantarayManifestReference(ref, ls)
+	if err != nil {
+		return nil, err
+	}
+	orig, err := manifest.NewMantarayManifestReference(ref, ls)
+	if err != nil {
+		return nil, err
+	}
+	return &Builder{
+		m:        m,
+		ls:       ls,
+		refSize:  len(ref.Bytes()),
+		orig:     orig,
+		origRoot: mantaray.NewNodeRef(ref.Bytes()),
+		changes:  make(map[string]*change),
+	}, nil
+}
+
+// Add adds ref at path with metadata, replacing any existing entry
+func (b *Builder) Add(ctx context.Context, p string, ref swarm.Address, metadata map[string]string) error {
+	p, err := cleanPath(p)
+	if err != nil {
+		return err
+	}
+	return b.add(ctx, p, ref, metadata)
+}
+
+// AddFile adds a file at path, setting its content type and file name metadata like bee does
+func (b *Builder) AddFile(ctx context.Context, p string, ref swarm.Address, contentType string) error {
+	metadata := map[string]string{
+		manifest.EntryMetadataFilenameKey: path.Base(p),
+	}
+	if contentType != "" {
+		metadata[manifest.EntryMetadataContentTypeKey] = contentType
+	}
+	return b.Add(ctx, p, ref, metadata)
+}
+
+// Replace points the existing entry at path to ref, keeping its metadata
+func (b *Builder) Replace(ctx context.Context, p string, ref swarm.Address) error {
+	p, err := cleanPath(p)
+	if err != nil {
+		return err
+	}
+	current, err := b.existing(ctx, p)
+	if err != nil {
+		return err
+	}
+	return b.add(ctx, p, ref, current.metadata)
+}
+
+// Remove removes the entry at path. Entries below it, like "a.html.gz" below "a.html", are kept.
+func (b *Builder) Remove(ctx context.Context, p string) error {
+	p, err := cleanPath(p)
+	if err != nil {
+		return err
+	}
+	if _, err = b.existing(ctx, p); err != nil {
+		return err
+	}
+	// mantaray removes the whole subtree of a path, so the entries below it are added back
+	descendants, err := b.descendants(ctx, p)
+	if err != nil {
+		return err
+	}
+	if err = b.track(ctx, p); err != nil {
+		return err
+	}
+	if err = b.loadPath(ctx, p); err != nil {
+		return err
+	}
+	if err = b.m.Remove(ctx, p); err != nil {
+		return err
+	}
+	for d, current := range descendants {
+		if err = b.add(ctx, d, current.ref, current.metadata); err != nil {
+			return err
+		}
+	}
+	b.changes[p].current = &snapshot{}
+	return nil
+}
+
+// SetIndexDocument sets the document served for the root and for directory paths
+func (b *Builder) SetIndexDocument(name string) {
+	b.indexDocument = name
+}
+
+// SetErrorDocument sets the document served for paths that are not found
+func (b *Builder) SetErrorDocument(p string) {
+	b.errorDocument = p
+}
+
+// SetRootMetadata adds metadata to the root entry of the manifest, next to the website documents
+func (b *Builder) SetRootMetadata(metadata map[string]string) {
+	if b.rootMetadata == nil {
+		b.rootMetadata = make(map[string]string)
+	}
+	for k, v := range metadata {
+		b.rootMetadata[k] = v
+	}
+}
+
+// Save stores the changed manifest nodes and returns the root reference of the manifest
+func (b *Builder) Save(ctx context.Context) (swarm.Address, error) {
+	if err := b.setRootMetadata(ctx); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	// nodes are saved concurrently
+	var saved atomic.Int64
+	ref, err := b.m.Store(ctx, func(int64) error {
+		saved.Add(1)
+		return nil
+	})
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	b.savedNodes = int(saved.Load())
+	return ref, nil
+}
+
+// Summary compares every changed path with its original entry
+func (b *Builder) Summary() *Summary {
+	summary := &Summary{SavedNodes: b.savedNodes}
+	for p, c := range b.changes {
+		if p == manifest.RootPath {
+			continue
+		}
+		switch {
+		case !c.orig.exists && c.current.exists:
+			summary.Added = append(summary.Added, p)
+		case c.orig.exists && !c.current.exists:
+			summary.Removed = append(summary.Removed, p)
+		case c.orig.exists && (!c.orig.ref.Equal(c.current.ref) || !maps.Equal(c.orig.metadata, c.current.metadata)):
+			summary.Modified = append(summary.Modified, p)
+		}
+	}
+	sort.Strings(summary.Added)
+	sort.Strings(summary.Modified)
+	sort.Strings(summary.Removed)
+	return summary
+}
+
+// setRootMetadata stores the website documents and root metadata on the root path, where bee looks for them
+func (b *Builder) setRootMetadata(ctx context.Context) error {
+	if b.indexDocument == "" && b.errorDocument == "" && len(b.rootMetadata) == 0 {
+		return nil
+	}
+	root, err := b.current(ctx, manifest.RootPath)
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]string)
+	for k, v := range root.metadata {
+		metadata[k] = v
+	}
+	for k, v := range b.rootMetadata {
+		metadata[k] = v
+	}
+	if b.indexDocument != "" {
+		metadata[manifest.WebsiteIndexDocumentSuffixKey] = b.indexDocument
+	}
+	if b.errorDocument != "" {
+		metadata[manifest.WebsiteErrorDocumentPathKey] = b.errorDocument
+	}
+	return b.add(ctx, manifest.RootPath, swarm.ZeroAddress, metadata)
+}
+
+// add adds an entry to the manifest and records the change
+func (b *Builder) add(ctx context.Context, p string, ref swarm.Address, metadata map[string]string) error {
+	if err := b.track(ctx, p); err != nil {
+		return err
+	}
+	if err := b.loadPath(ctx, p); err != nil {
+		return err
+	}
+	if err := b.m.Add(ctx, p, manifest.NewEntry(ref, metadata)); err != nil {
+		return err
+	}
+	b.changes[p].current = &snapshot{exists: true, ref: ref, metadata: metadata}
+	return nil
+}
+
+// track records the original entry at path before it is changed for the first time
+func (b *Builder) track(ctx context.Context, p string) error {
+	if _, ok := b.changes[p]; ok {
+		return nil
+	}
+	orig, err := b.original(ctx, p)
+	if err != nil {
+		return err
+	}
+	b.changes[p] = &change{orig: orig, current: orig}
+	return nil
+}
+
+// loadPath loads every node on path, including the node at path itself. Mantaray only
+// stores nodes again that it loaded while adding an entry, so an entry is added below
+// path and removed right away. Paths never contain the NUL byte used for it. The entry
+// is a full reference, as mantaray only learns the reference size of a loaded node from
+// the entries added to it.
+func (b *Builder) loadPath(ctx context.Context, p string) error {
+	tmp := p + "\x00"
+	if err := b.m.Add(ctx, tmp, manifest.NewEntry(swarm.NewAddress(make([]byte, b.refSize)), nil)); err != nil {
+		return err
+	}
+	return b.m.Remove(ctx, tmp)
+}
+
+// descendants returns the current entries below path
+func (b *Builder) descendants(ctx context.Context, p string) (map[string]*snapshot, error) {
+	paths := make(map[string]bool)
+	if b.origRoot != nil {
+		err := b.origRoot.WalkNode(ctx, []byte(p), b.ls, func(path []byte, node *mantaray.Node, err error) error {
+			if err != nil {
+				return err
+			}
+			if node.IsValueType() && string(path) != p {
+				paths[string(path)] = true
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, mantaray.ErrNotFound) {
+			return nil, err
+		}
+	}
+	for q := range b.changes {
+		if q != p && strings.HasPrefix(q, p) {
+			paths[q] = true
+		}
+	}
+
+	descendants := make(map[string]*snapshot)
+	for q := range paths {
+		current, err := b.current(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if current.exists {
+			descendants[q] = current
+		}
+	}
+	return descendants, nil
+}
+
+// current returns the entry at path, including the changes made by the builder. Lookups
+// never go through the manifest being changed, as loading its nodes outside of an add
+// hides later changes from mantaray.
+func (b *Builder) current(ctx context.Context, p string) (*snapshot, error) {
+	if c, ok := b.changes[p]; ok {
+		return c.current, nil
+	}
+	return b.original(ctx, p)
+}
+
+// existing returns the current entry at path or an error if there is none
+func (b *Builder) existing(ctx context.Context, p string) (*snapshot, error) {
+	current, err := b.current(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if !current.exists {
+		return nil, fmt.Errorf("%w: %s", blockstore.ErrNotFound, p)
+	}
+	return current, nil
+}
+
+func (b *Builder) original(ctx context.Context, p string) (*snapshot, error) {
+	if b.orig == nil {
+		return &snapshot{}, nil
+	}
+	e, err := b.orig.Lookup(ctx, p)
+	if errors.Is(err, manifest.ErrNotFound) {
+		return &snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{exists: true, ref: e.Reference(), metadata: e.Metadata()}, nil
+}
+
+// cleanPath turns p into a manifest path without a leading slash
+func cleanPath(p string) (string, error) {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" || strings.ContainsRune(p, 0) {
+		return "", ErrInvalidPath
+	}
+	return p, nil
+}
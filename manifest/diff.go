@@ -0,0 +1,322 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+// layout of a serialised mantaray node, see the mantaray package of bee
+const (
+	nodeObfuscationKeySize = 32
+	nodeVersionHashSize    = 31
+	nodeHeaderSize         = nodeObfuscationKeySize + nodeVersionHashSize + 1
+	nodeForkIndexSize      = 32
+	nodeForkHeaderSize     = 2
+	nodeForkPrefixSize     = 30
+	nodeForkMetadataSize   = 2
+
+	nodeTypeValue        = uint8(2)
+	nodeTypeWithMetadata = uint8(16)
+)
+
+var (
+	errInvalidNode = errors.New("invalid manifest node")
+
+	nodeVersion01 = nodeVersionHash("mantaray:0.1")
+	nodeVersion02 = nodeVersionHash("mantaray:0.2")
+)
+
+// Diff lists the paths that differ between two manifests
+type Diff struct {
+	Added    []*Change
+	Removed  []*Change
+	Modified []*Change
+}
+
+// Change is a path that differs between two manifests. Old is nil for added paths and New is
+// nil for removed paths.
+type Change struct {
+	Path     string
+	Old      *Value
+	New      *Value
+	Metadata []*MetadataChange
+}
+
+// Value is the entry at a path of a manifest
+type Value struct {
+	Reference swarm.Address
+	Metadata  map[string]string
+}
+
+// MetadataChange is a metadata key of a path that differs between two manifests. Old or New is
+// empty if the key was added or removed.
+type MetadataChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// DiffManifests compares the Mantaray manifests at from and to, loading their nodes with
+// DownloadChunk. Subtrees with the same node reference in both manifests are not loaded.
+func DiffManifests(ctx context.Context, c blockstore.ChunkStore, from, to swarm.Address) (*Diff, error) {
+	d := &differ{ls: newReadonlyLoadSaver(c), diff: &Diff{}}
+	if !from.Equal(to) {
+		a, err := d.load(ctx, from.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.load(ctx, to.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if err = d.nodes(ctx, "", a, b); err != nil {
+			return nil, err
+		}
+	}
+	for _, changes := range [][]*Change{d.diff.Added, d.diff.Removed, d.diff.Modified} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	}
+	return d.diff, nil
+}
+
+type differ struct {
+	ls   file.LoadSaver
+	diff *Diff
+}
+
+type node struct {
+	entry []byte
+	forks map[byte]*fork
+}
+
+type fork struct {
+	prefix   []byte
+	nodeType uint8
+	ref      []byte
+	metadata map[string]string
+}
+
+// nodes compares the forks of two nodes at path p. Forks that start with the same byte but have
+// different prefixes are compared by listing all their entries.
+func (d *differ) nodes(ctx context.Context, p string, a, b *node) error {
+	keys := make(map[byte]bool)
+	for k := range a.forks {
+		keys[k] = true
+	}
+	for k := range b.forks {
+		keys[k] = true
+	}
+	for k := range keys {
+		fa, fb := a.forks[k], b.forks[k]
+		if fa != nil && fb != nil && bytes.Equal(fa.prefix, fb.prefix) {
+			if err := d.forks(ctx, p+string(fa.prefix), fa, fb); err != nil {
+				return err
+			}
+			continue
+		}
+		old := make(map[string]*Value)
+		if err := d.values(ctx, p, fa, old); err != nil {
+			return err
+		}
+		current := make(map[string]*Value)
+		if err := d.values(ctx, p, fb, current); err != nil {
+			return err
+		}
+		for q := range old {
+			d.compare(q, old[q], current[q])
+		}
+		for q := range current {
+			if old[q] == nil {
+				d.compare(q, nil, current[q])
+			}
+		}
+	}
+	return nil
+}
+
+// forks compares two forks at path p, the subtrees below them are skipped if they are the same node
+func (d *differ) forks(ctx context.Context, p string, fa, fb *fork) error {
+	if bytes.Equal(fa.ref, fb.ref) {
+		// only the value flag and the metadata kept in the forks can differ
+		if fa.nodeType&nodeTypeValue == fb.nodeType&nodeTypeValue &&
+			(fa.nodeType&nodeTypeValue == 0 || maps.Equal(fa.metadata, fb.metadata)) {
+			return nil
+		}
+		n, err := d.load(ctx, fa.ref)
+		if err != nil {
+			return err
+		}
+		d.compare(p, fa.value(n), fb.value(n))
+		return nil
+	}
+
+	a, err := d.load(ctx, fa.ref)
+	if err != nil {
+		return err
+	}
+	b, err := d.load(ctx, fb.ref)
+	if err != nil {
+		return err
+	}
+	d.compare(p, fa.value(a), fb.value(b))
+	return d.nodes(ctx, p, a, b)
+}
+
+// values adds the entries of the subtree below f to values
+func (d *differ) values(ctx context.Context, p string, f *fork, values map[string]*Value) error {
+	if f == nil {
+		return nil
+	}
+	p += string(f.prefix)
+	n, err := d.load(ctx, f.ref)
+	if err != nil {
+		return err
+	}
+	if v := f.value(n); v != nil {
+		values[p] = v
+	}
+	for _, child := range n.forks {
+		if err = d.values(ctx, p, child, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare records the change at path p, if there is one
+func (d *differ) compare(p string, old, current *Value) {
+	switch {
+	case old == nil && current == nil:
+	case old == nil:
+		d.diff.Added = append(d.diff.Added, &Change{Path: p, New: current})
+	case current == nil:
+		d.diff.Removed = append(d.diff.Removed, &Change{Path: p, Old: old})
+	case !old.Reference.Equal(current.Reference) || !maps.Equal(old.Metadata, current.Metadata):
+		d.diff.Modified = append(d.diff.Modified, &Change{
+			Path:     p,
+			Old:      old,
+			New:      current,
+			Metadata: metadataChanges(old.Metadata, current.Metadata),
+		})
+	}
+}
+
+func metadataChanges(old, current map[string]string) []*MetadataChange {
+	var changes []*MetadataChange
+	for k, v := range old {
+		if current[k] != v {
+			changes = append(changes, &MetadataChange{Key: k, Old: v, New: current[k]})
+		}
+	}
+	for k, v := range current {
+		if _, ok := old[k]; !ok {
+			changes = append(changes, &MetadataChange{Key: k, New: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// value returns the entry of the node n that f points to, or nil if f is not a value
+func (f *fork) value(n *node) *Value {
+	if f.nodeType&nodeTypeValue == 0 {
+		return nil
+	}
+	ref := swarm.ZeroAddress
+	if !isZeroReference(n.entry) {
+		ref = swarm.NewAddress(n.entry)
+	}
+	return &Value{Reference: ref, Metadata: f.metadata}
+}
+
+func (d *differ) load(ctx context.Context, ref []byte) (*node, error) {
+	data, err := d.ls.Load(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	n, err := unmarshalNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w %x: %w", errInvalidNode, ref, err)
+	}
+	return n, nil
+}
+
+// unmarshalNode decodes a serialised mantaray node. The mantaray package does not expose
+// the references of the forks of a node, which are needed to skip identical subtrees.
+func unmarshalNode(data []byte) (*node, error) {
+	if len(data) < nodeHeaderSize {
+		return nil, errors.New("too short")
+	}
+	key := data[:nodeObfuscationKeySize]
+	data = append([]byte{}, data...)
+	for i := nodeObfuscationKeySize; i < len(data); i++ {
+		data[i] ^= key[i%nodeObfuscationKeySize]
+	}
+
+	version := data[nodeObfuscationKeySize : nodeObfuscationKeySize+nodeVersionHashSize]
+	withMetadata := bytes.Equal(version, nodeVersion02)
+	if !withMetadata && !bytes.Equal(version, nodeVersion01) {
+		return nil, errors.New("unknown version")
+	}
+	refSize := int(data[nodeHeaderSize-1])
+	offset := nodeHeaderSize + refSize
+	if len(data) < offset+nodeForkIndexSize {
+		return nil, errors.New("too short")
+	}
+
+	n := &node{entry: data[nodeHeaderSize:offset], forks: make(map[byte]*fork)}
+	index := data[offset : offset+nodeForkIndexSize]
+	offset += nodeForkIndexSize
+	for i := 0; i < 256; i++ {
+		if index[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		size := nodeForkHeaderSize + nodeForkPrefixSize + refSize
+		if len(data) < offset+size {
+			return nil, errors.New("too short")
+		}
+		f := &fork{nodeType: data[offset], ref: data[offset+size-refSize : offset+size]}
+		prefixLen := int(data[offset+1])
+		if prefixLen == 0 || prefixLen > nodeForkPrefixSize {
+			return nil, fmt.Errorf("invalid prefix length %d", prefixLen)
+		}
+		f.prefix = data[offset+nodeForkHeaderSize : offset+nodeForkHeaderSize+prefixLen]
+		offset += size
+
+		if withMetadata && f.nodeType&nodeTypeWithMetadata != 0 {
+			if len(data) < offset+nodeForkMetadataSize {
+				return nil, errors.New("too short")
+			}
+			size = int(binary.BigEndian.Uint16(data[offset : offset+nodeForkMetadataSize]))
+			offset += nodeForkMetadataSize
+			if len(data) < offset+size {
+				return nil, errors.New("too short")
+			}
+			if size > 0 {
+				if err := json.Unmarshal(data[offset:offset+size], &f.metadata); err != nil {
+					return nil, err
+				}
+			}
+			offset += size
+		}
+		n.forks[byte(i)] = f
+	}
+	return n, nil
+}
+
+func nodeVersionHash(version string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(version))
+	return h.Sum(nil)[:nodeVersionHashSize]
+}
@@ -0,0 +1,412 @@
+package manifest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/bee"
+	"github.com/asabya/swarm-blockstore/bee/mock"
+	"github.com/asabya/swarm-blockstore/manifest"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func newTestClient(t *testing.T) *bee.Client {
+	t.Helper()
+	return mock.NewTestClient(t, mock.TestServerOptions{})
+}
+
+func uploadBlob(t *testing.T, client *bee.Client, data string) swarm.Address {
+	t.Helper()
+	ref, err := client.UploadBlob(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func downloadFile(t *testing.T, client *bee.Client, ref swarm.Address, name string) string {
+	t.Helper()
+	r, _, err := client.DownloadFileBzz(ref, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBuilder(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	files := map[string]string{
+		"index.html":     "<h1>index</h1>",
+		"404.html":       "<h1>not found</h1>",
+		"css/style.css":  "body {}",
+		"/img/logo.svg":  "<svg/>",
+		"docs/../a.html": "a",
+	}
+
+	ls, err := manifest.NewClientLoadSaver(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := manifest.NewBuilder(ls, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Add(ctx, "/", swarm.ZeroAddress, nil); err == nil {
+		t.Fatal("expected error for empty path")
+	}
+	for p, data := range files {
+		if err = b.AddFile(ctx, p, uploadBlob(t, client, data), "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.SetIndexDocument("index.html")
+	b.SetErrorDocument("404.html")
+	root, err := b.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"css/style.css": "body {}",
+		"img/logo.svg":  "<svg/>",
+		"a.html":        "a",
+		"":              "<h1>index</h1>",
+		"missing.html":  "<h1>not found</h1>",
+	} {
+		if got := downloadFile(t, client, root, name); got != want {
+			t.Fatalf("%q: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestListAndResolve(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	ls, err := manifest.NewClientLoadSaver(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := manifest.NewBuilder(ls, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []struct {
+		path, data, contentType string
+	}{
+		{"docs/index.html", "<h1>docs</h1>", "text/html"},
+		{"index.html", "<h1>index</h1>", "text/html"},
+		{"style.css", "body {}", "text/css"},
+	}
+	refs := make(map[string]swarm.Address)
+	for _, f := range files {
+		refs[f.path] = uploadBlob(t, client, f.data)
+		if err = b.AddFile(ctx, f.path, refs[f.path], f.contentType); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.SetIndexDocument("index.html")
+	root, err := b.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.ListManifest(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(files) {
+		t.Fatalf("expected %d entries, got %d", len(files), len(entries))
+	}
+	for i, f := range files {
+		e := entries[i]
+		if e.Path != f.path || !e.Reference.Equal(refs[f.path]) || e.ContentType != f.contentType || e.Size != int64(len(f.data)) {
+			t.Fatalf("unexpected entry %+v for %s", e, f.path)
+		}
+	}
+
+	for p, want := range map[string]string{
+		"style.css":  "style.css",
+		"/style.css": "style.css",
+		"":           "index.html",
+		"docs":       "docs/index.html",
+		"docs/":      "docs/index.html",
+	} {
+		e, err := client.ResolvePath(ctx, root, p)
+		if err != nil {
+			t.Fatalf("%q: %v", p, err)
+		}
+		if e.Path != want || !e.Reference.Equal(refs[want]) {
+			t.Fatalf("%q: expected %s, got %s", p, want, e.Path)
+		}
+	}
+	if _, err = client.ResolvePath(ctx, root, "missing.html"); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// manifests built by the node list the same way
+	uploaded, err := client.UploadFileBzz([]byte("{}"), "data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err = client.ListManifest(ctx, uploaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Path != "data.json" || entries[0].Size != 2 {
+		t.Fatalf("unexpected entries of uploaded file %+v", entries)
+	}
+
+	// the size of erasure coded data leaves out the redundancy level in the span
+	data := bytes.Repeat([]byte{1}, 3*swarm.ChunkSize+1)
+	uploaded, err = client.UploadFileBzz(data, "data.bin", blockstore.WithRedundancyLevel(redundancy.MEDIUM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := client.ResolvePath(ctx, uploaded, "data.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Size != int64(len(data)) {
+		t.Fatalf("expected size %d, got %d", len(data), e.Size)
+	}
+}
+
+func TestLoadBuilder(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	ls, err := manifest.NewClientLoadSaver(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := manifest.NewBuilder(ls, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"index.html", "old.html", "css/style.css", "css/print.css", "js/app.js", "js/app.js.map", "img/a.png", "img/b.png"} {
+		if err = b.AddFile(ctx, p, uploadBlob(t, client, p), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := b.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := b.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	initialNodes := summary.SavedNodes
+	if len(summary.Added) != 8 {
+		t.Fatalf("expected 8 added paths, got %v", summary.Added)
+	}
+
+	e, err := manifest.LoadBuilder(ls, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Replace(ctx, "missing.css", swarm.ZeroAddress); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err = e.Replace(ctx, "css/style.css", uploadBlob(t, client, "body { color: red }")); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.AddFile(ctx, "new.html", uploadBlob(t, client, "new"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Remove(ctx, "old.html"); err != nil {
+		t.Fatal(err)
+	}
+	// entries below a removed path are kept
+	if err = e.Remove(ctx, "js/app.js"); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.AddFile(ctx, "tmp.html", uploadBlob(t, client, "tmp"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Remove(ctx, "tmp.html"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := e.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary = e.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalStrings(summary.Added, []string{"new.html"}) || !equalStrings(summary.Modified, []string{"css/style.css"}) || !equalStrings(summary.Removed, []string{"js/app.js", "old.html"}) {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if summary.SavedNodes == 0 || summary.SavedNodes >= initialNodes {
+		t.Fatalf("expected fewer than %d saved nodes, got %d", initialNodes, summary.SavedNodes)
+	}
+
+	if got := downloadFile(t, client, updated, "css/style.css"); got != "body { color: red }" {
+		t.Fatalf("unexpected replaced file %q", got)
+	}
+	if got := downloadFile(t, client, updated, "img/b.png"); got != "img/b.png" {
+		t.Fatalf("unexpected unchanged file %q", got)
+	}
+	if got := downloadFile(t, client, updated, "js/app.js.map"); got != "js/app.js.map" {
+		t.Fatalf("unexpected file below removed path %q", got)
+	}
+	if got := downloadFile(t, client, root, "css/style.css"); got != "css/style.css" {
+		t.Fatalf("original manifest changed: %q", got)
+	}
+	entries, err := client.ListManifest(ctx, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 7 {
+		t.Fatalf("expected 7 entries, got %d", len(entries))
+	}
+
+	// collections uploaded through the node can be changed as well
+	uploaded, err := client.UploadFileBzz([]byte("{}"), "data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err = manifest.LoadBuilder(ls, uploaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.AddFile(ctx, "more.json", uploadBlob(t, client, "[]"), "application/json"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err = e.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := downloadFile(t, client, updated, "more.json"); got != "[]" {
+		t.Fatalf("unexpected added file %q", got)
+	}
+}
+
+// countingClient counts the chunks downloaded through it
+type countingClient struct {
+	blockstore.Client
+	downloads atomic.Int64
+}
+
+func (c *countingClient) DownloadChunk(ctx context.Context, address swarm.Address, opts ...blockstore.DownloadOption) (swarm.Chunk, error) {
+	c.downloads.Add(1)
+	return c.Client.DownloadChunk(ctx, address, opts...)
+}
+
+func TestDiffManifests(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	ls, err := manifest.NewClientLoadSaver(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := manifest.NewBuilder(ls, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{"index.html", "old.html", "css/style.css"}
+	for i := 0; i < 40; i++ {
+		paths = append(paths, fmt.Sprintf("assets/%c%d.png", 'a'+i%20, i))
+	}
+	for _, p := range paths {
+		if err = b.AddFile(ctx, p, uploadBlob(t, client, p), "text/html"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from, err := b.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := b.Summary().SavedNodes
+
+	e, err := manifest.LoadBuilder(ls, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	styleRef := uploadBlob(t, client, "body { color: red }")
+	if err = e.Replace(ctx, "css/style.css", styleRef); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.AddFile(ctx, "index.html", uploadBlob(t, client, "index.html"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.AddFile(ctx, "new.html", uploadBlob(t, client, "new"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Remove(ctx, "old.html"); err != nil {
+		t.Fatal(err)
+	}
+	e.SetIndexDocument("index.html")
+	to, err := e.Save(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counting := &countingClient{Client: client}
+	diff, err := manifest.DiffManifests(ctx, counting, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = nil
+	for _, changes := range [][]*manifest.Change{diff.Added, diff.Removed, diff.Modified} {
+		for _, c := range changes {
+			paths = append(paths, c.Path)
+		}
+	}
+	if !equalStrings(paths, []string{"/", "new.html", "old.html", "css/style.css", "index.html"}) {
+		t.Fatalf("unexpected changed paths %v", paths)
+	}
+	if diff.Added[1].Old != nil || diff.Removed[0].New != nil {
+		t.Fatal("expected no old value for added paths and no new value for removed paths")
+	}
+	if style := diff.Modified[0]; !style.New.Reference.Equal(styleRef) || len(style.Metadata) != 0 {
+		t.Fatalf("unexpected change %+v", style)
+	}
+	index := diff.Modified[1]
+	if !index.Old.Reference.Equal(index.New.Reference) || len(index.Metadata) != 1 {
+		t.Fatalf("unexpected change %+v", index)
+	}
+	if m := index.Metadata[0]; m.Key != "Content-Type" || m.Old != "text/html" || m.New != "text/plain" {
+		t.Fatalf("unexpected metadata change %+v", m)
+	}
+	// the assets subtree is the same in both manifests and is not loaded
+	if n := counting.downloads.Load(); n >= int64(nodes) {
+		t.Fatalf("expected identical subtrees to be skipped, downloaded %d chunks for %d nodes", n, nodes)
+	}
+
+	diff, err = client.DiffManifests(ctx, from, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Added)+len(diff.Removed)+len(diff.Modified) != 0 {
+		t.Fatalf("expected no changes, got %+v", diff)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
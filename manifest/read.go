@@ -0,0 +1,133 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/ethersphere/bee/v2/pkg/bmt"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/manifest/mantaray"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// Entry is a file in a manifest
+type Entry struct {
+	Path        string
+	Reference   swarm.Address
+	ContentType string
+	// Size is the length of the referenced data, or -1 for encrypted references
+	Size     int64
+	Metadata map[string]string
+}
+
+// ListManifest walks the Mantaray manifest at ref and returns its entries sorted by path
+func ListManifest(ctx context.Context, c blockstore.ChunkStore, ref swarm.Address) ([]*Entry, error) {
+	ls := newReadonlyLoadSaver(c)
+	var entries []*Entry
+	err := mantaray.NewNodeRef(ref.Bytes()).WalkNode(ctx, []byte{}, ls, func(p []byte, node *mantaray.Node, err error) error {
+		if err != nil {
+			return err
+		}
+		if !node.IsValueType() || isZeroReference(node.Entry()) {
+			return nil
+		}
+		e, err := newEntry(ctx, c, string(p), swarm.NewAddress(node.Entry()), node.Metadata())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ResolvePath returns the entry served for path in the manifest at ref. Directory paths
+// and the empty path resolve to the index document, if the manifest has one.
+func ResolvePath(ctx context.Context, c blockstore.ChunkStore, ref swarm.Address, p string) (*Entry, error) {
+	m, err := manifest.NewMantarayManifestReference(ref, newReadonlyLoadSaver(c))
+	if err != nil {
+		return nil, err
+	}
+
+	var indexDocument string
+	if root, err := m.Lookup(ctx, manifest.RootPath); err == nil {
+		indexDocument = root.Metadata()[manifest.WebsiteIndexDocumentSuffixKey]
+	}
+
+	candidates := []string{}
+	if p, err := cleanPath(p); err == nil {
+		candidates = append(candidates, p)
+		if indexDocument != "" {
+			candidates = append(candidates, p+"/"+indexDocument)
+		}
+	} else if indexDocument != "" {
+		candidates = append(candidates, indexDocument)
+	}
+
+	for _, candidate := range candidates {
+		e, err := m.Lookup(ctx, candidate)
+		if errors.Is(err, manifest.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return newEntry(ctx, c, candidate, e.Reference(), e.Metadata())
+	}
+	return nil, fmt.Errorf("%w: %s", blockstore.ErrNotFound, strings.TrimPrefix(p, "/"))
+}
+
+func newEntry(ctx context.Context, c blockstore.ChunkStore, p string, ref swarm.Address, metadata map[string]string) (*Entry, error) {
+	size, err := referenceSize(ctx, c, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		Path:        p,
+		Reference:   ref,
+		ContentType: metadata[manifest.EntryMetadataContentTypeKey],
+		Size:        size,
+		Metadata:    metadata,
+	}, nil
+}
+
+// referenceSize reads the length of the data at ref from the span of its root chunk
+func referenceSize(ctx context.Context, c blockstore.ChunkStore, ref swarm.Address) (int64, error) {
+	if len(ref.Bytes()) != swarm.HashSize {
+		return -1, nil
+	}
+	ch, err := c.DownloadChunk(ctx, ref)
+	if err != nil {
+		return 0, err
+	}
+	if len(ch.Data()) < swarm.SpanSize {
+		return 0, fmt.Errorf("invalid chunk %s", ref)
+	}
+	_, span := redundancy.DecodeSpan(ch.Data()[:swarm.SpanSize])
+	return int64(bmt.LengthFromSpan(span)), nil
+}
+
+func newReadonlyLoadSaver(c blockstore.ChunkStore) file.LoadSaver {
+	return loadsave.NewReadonly(storage.GetterFunc(func(ctx context.Context, address swarm.Address) (swarm.Chunk, error) {
+		return c.DownloadChunk(ctx, address)
+	}))
+}
+
+func isZeroReference(ref []byte) bool {
+	for _, b := range ref {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
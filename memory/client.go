@@ -0,0 +1,537 @@
+// Package memory is a blockstore.Client that keeps chunks in memory, for tests that should not
+// start a bee node. Chunks are validated and data is split like bee does, so references match
+// the ones a node returns.
+package memory
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	bsmanifest "github.com/asabya/swarm-blockstore/manifest"
+	bstar "github.com/asabya/swarm-blockstore/tar"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	feedMetadataEntryOwner = "swarm-feed-owner"
+	feedMetadataEntryTopic = "swarm-feed-topic"
+	feedMetadataEntryType  = "swarm-feed-type"
+
+	// fileContentType is the content type bee.Client uploads single files with
+	fileContentType = "application/json"
+)
+
+var (
+	errACTNotSupported   = errors.New("access control is not supported in memory")
+	errInvalidFeedUpdate = errors.New("invalid feed update")
+)
+
+// Client is an in-memory blockstore.Client
+type Client struct {
+	store *inmemchunkstore.ChunkStore
+
+	mtx     sync.Mutex
+	tags    map[uint32]*tag
+	lastTag uint32
+	pins    map[string]struct{}
+}
+
+// tag counts the chunks uploaded with it. Stored chunks are synced at once.
+type tag struct {
+	total  int64
+	synced int64
+}
+
+// NewClient returns an empty in-memory client
+func NewClient() *Client {
+	return &Client{
+		store: inmemchunkstore.New(),
+		tags:  make(map[uint32]*tag),
+		pins:  make(map[string]struct{}),
+	}
+}
+
+// uploadSettings are the upload options that apply in memory, the batch ID is not checked
+type uploadSettings struct {
+	level   redundancy.Level
+	pin     bool
+	encrypt bool
+	tag     uint32
+	bzz     blockstore.BzzOptions
+	result  *blockstore.UploadResult
+}
+
+func newUploadSettings(opts []blockstore.UploadOption) (uploadSettings, error) {
+	o := blockstore.NewUploadOptions(opts...)
+	if o.ACT != nil && *o.ACT {
+		return uploadSettings{}, errACTNotSupported
+	}
+	u := uploadSettings{tag: o.Tag, bzz: o.Bzz, result: o.Result}
+	if o.RedundancyLevel != nil {
+		u.level = *o.RedundancyLevel
+	}
+	if o.Pin != nil {
+		u.pin = *o.Pin
+	}
+	if o.Encrypt != nil {
+		u.encrypt = *o.Encrypt
+	}
+	// bee does not pin or tag direct uploads, and a call can not ask for both
+	if o.Deferred != nil && !*o.Deferred {
+		if u.pin || u.tag > 0 {
+			return uploadSettings{}, blockstore.ErrDirectPinOrTag
+		}
+	}
+	return u, nil
+}
+
+// setResult sets the result of a successful upload, the client stores every upload and pushes none
+func (u uploadSettings) setResult() {
+	if u.result != nil {
+		*u.result = blockstore.UploadResult{Tag: u.tag}
+	}
+}
+
+// CheckConnection always succeeds
+func (c *Client) CheckConnection() bool {
+	return true
+}
+
+// UploadSOC validates the signature of the single owner chunk of owner with id and stores it
+func (c *Client) UploadSOC(owner, id, signature string, data []byte, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := newUploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ownerBytes, err := hex.DecodeString(owner)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+	}
+	idBytes, err := hex.DecodeString(id)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+	}
+	ch, err := cac.NewWithDataSpan(data)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	s, err := soc.NewSigned(idBytes, ch, ownerBytes, sig)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+	}
+	sch, err := s.Chunk()
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", blockstore.ErrInvalidSOC, err)
+	}
+	if !soc.Valid(sch) {
+		return swarm.ZeroAddress, blockstore.ErrInvalidSOC
+	}
+	// single owner chunks can be updated, unlike content addressed ones
+	if err = c.store.Replace(context.Background(), sch); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	c.pin(sch.Address(), u.pin)
+	u.setResult()
+	return sch.Address(), nil
+}
+
+// UploadChunk stores the content addressed chunk with the data of ch. Like bee, the address is
+// computed from the data and chunk uploads are never pinned.
+func (c *Client) UploadChunk(ch swarm.Chunk, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := newUploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	cch, err := cac.NewWithDataSpan(ch.Data())
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if err = c.putter(u).Put(context.Background(), cch); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	u.setResult()
+	return cch.Address(), nil
+}
+
+// DownloadChunk returns the chunk at address or blockstore.ErrNotFound
+func (c *Client) DownloadChunk(ctx context.Context, address swarm.Address, _ ...blockstore.DownloadOption) (swarm.Chunk, error) {
+	ch, err := c.store.Get(ctx, address)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", blockstore.ErrNotFound, address)
+	}
+	return ch, err
+}
+
+// UploadBlob splits data into chunks like the /bytes endpoint of bee and returns the root reference
+func (c *Client) UploadBlob(data io.Reader, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := newUploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ref, err := c.split(u, data)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	c.pin(ref, u.pin)
+	u.setResult()
+	return ref, nil
+}
+
+// DownloadBlob joins the data at address
+func (c *Client) DownloadBlob(address swarm.Address, _ ...blockstore.DownloadOption) (io.ReadCloser, int, error) {
+	r, _, err := c.join(context.Background(), address)
+	if err != nil {
+		return nil, statusCode(err), err
+	}
+	return r, http.StatusOK, nil
+}
+
+// UploadFileBzz stores data in a manifest with fileName as the index document. The manifest is
+// built like the /bzz endpoint of bee builds it, and then changed like bee.Client changes it.
+func (c *Client) UploadFileBzz(data []byte, fileName string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := newUploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if err = u.bzz.Validate([]string{fileName}); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ctx := context.Background()
+	ref, err := c.split(u, bytes.NewReader(data))
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ls := c.loadSaver(u)
+	m, err := manifest.NewDefaultManifest(ls, u.encrypt)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	rootMetadata := map[string]string{manifest.WebsiteIndexDocumentSuffixKey: fileName}
+	if err = m.Add(ctx, manifest.RootPath, manifest.NewEntry(swarm.ZeroAddress, rootMetadata)); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	fileMetadata := map[string]string{
+		manifest.EntryMetadataContentTypeKey: fileContentType,
+		manifest.EntryMetadataFilenameKey:    fileName,
+	}
+	if err = m.Add(ctx, fileName, manifest.NewEntry(ref, fileMetadata)); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if ref, err = m.Store(ctx); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if u.bzz.ErrorDocument == "" && len(u.bzz.Metadata) == 0 {
+		c.pin(ref, u.pin)
+		u.setResult()
+		return ref, nil
+	}
+	b, err := bsmanifest.LoadBuilder(ls, ref)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return c.saveManifest(ctx, b, u)
+}
+
+// UploadBzz stores the files of the tar stream in a manifest
+func (c *Client) UploadBzz(data *bstar.Stream, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := newUploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if err = u.bzz.Validate(data.Paths()); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ctx := context.Background()
+	b, err := bsmanifest.NewBuilder(c.loadSaver(u), u.encrypt)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	tr := tar.NewReader(bytes.NewReader(data.Output().Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		ref, err := c.split(u, tr)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if err = b.AddFile(ctx, hdr.Name, ref, mime.TypeByExtension(path.Ext(hdr.Name))); err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+	b.SetIndexDocument(u.bzz.IndexDocument)
+	return c.saveManifest(ctx, b, u)
+}
+
+// saveManifest adds the documents and metadata of u to the manifest and stores it
+func (c *Client) saveManifest(ctx context.Context, b *bsmanifest.Builder, u uploadSettings) (swarm.Address, error) {
+	b.SetErrorDocument(u.bzz.ErrorDocument)
+	b.SetRootMetadata(u.bzz.Metadata)
+	ref, err := b.Save(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	c.pin(ref, u.pin)
+	u.setResult()
+	return ref, nil
+}
+
+// DownloadBzz returns the index document of the manifest at address
+func (c *Client) DownloadBzz(address swarm.Address, opts ...blockstore.DownloadOption) ([]byte, int, error) {
+	r, _, err := c.DownloadFileBzz(address, "", opts...)
+	if err != nil {
+		return nil, statusCode(err), err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return data, http.StatusOK, nil
+}
+
+// DownloadFileBzz returns the document served for filename in the manifest at address. Directory
+// paths are served their index document and missing paths the error document, like bee does.
+func (c *Client) DownloadFileBzz(address swarm.Address, filename string, _ ...blockstore.DownloadOption) (io.ReadCloser, uint64, error) {
+	ctx := context.Background()
+	e, err := bsmanifest.ResolvePath(ctx, c, address, filename)
+	if errors.Is(err, blockstore.ErrNotFound) {
+		if errorDocument := c.rootMetadata(ctx, address)[manifest.WebsiteErrorDocumentPathKey]; errorDocument != "" {
+			e, err = bsmanifest.ResolvePath(ctx, c, address, errorDocument)
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	r, size, err := c.join(ctx, e.Reference)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r, uint64(size), nil
+}
+
+// rootMetadata returns the metadata of the root entry of the manifest at ref, if it has one
+func (c *Client) rootMetadata(ctx context.Context, ref swarm.Address) map[string]string {
+	m, err := manifest.NewMantarayManifestReference(ref, loadsave.NewReadonly(c.store))
+	if err != nil {
+		return nil
+	}
+	e, err := m.Lookup(ctx, manifest.RootPath)
+	if err != nil {
+		return nil
+	}
+	return e.Metadata()
+}
+
+// DeleteReference unpins address
+func (c *Client) DeleteReference(address swarm.Address) error {
+	c.mtx.Lock()
+	delete(c.pins, address.ByteString())
+	c.mtx.Unlock()
+	return nil
+}
+
+// IsPinned reports whether address was uploaded with pinning and not unpinned since
+func (c *Client) IsPinned(address swarm.Address) (bool, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	_, ok := c.pins[address.ByteString()]
+	return ok, nil
+}
+
+// CreateTag returns a new tag
+func (c *Client) CreateTag(_ swarm.Address) (uint32, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.lastTag++
+	c.tags[c.lastTag] = &tag{}
+	return c.lastTag, nil
+}
+
+// GetTag returns the number of chunks uploaded with tag, how many of them were processed and synced
+func (c *Client) GetTag(uid uint32) (int64, int64, int64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	t, ok := c.tags[uid]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("%w: tag %d", blockstore.ErrNotFound, uid)
+	}
+	return t.total, t.synced, t.synced, nil
+}
+
+// CreateFeedManifest stores the manifest of the sequence feed of owner and topic, it is the same
+// manifest bee creates
+func (c *Client) CreateFeedManifest(owner, topic string, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	u, err := newUploadSettings(opts)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ownerAddr, topicBytes, err := parseFeed(owner, topic)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ctx := context.Background()
+	m, err := manifest.NewDefaultManifest(c.loadSaver(u), false)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	metadata := map[string]string{
+		feedMetadataEntryOwner: hex.EncodeToString(ownerAddr.Bytes()),
+		feedMetadataEntryTopic: hex.EncodeToString(topicBytes),
+		feedMetadataEntryType:  feeds.Sequence.String(),
+	}
+	if err = m.Add(ctx, manifest.RootPath, manifest.NewEntry(swarm.NewAddress(make([]byte, swarm.HashSize)), metadata)); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ref, err := m.Store(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	c.pin(ref, u.pin)
+	u.setResult()
+	return ref, nil
+}
+
+// GetLatestFeedManifest returns the reference in the latest update of the sequence feed of owner
+// and topic, with the hex encoded index of the update and of the next one
+func (c *Client) GetLatestFeedManifest(owner, topic string) (swarm.Address, string, string, error) {
+	ownerAddr, topicBytes, err := parseFeed(owner, topic)
+	if err != nil {
+		return swarm.ZeroAddress, "", "", err
+	}
+	ch, cur, next, err := sequence.NewFinder(c.store, feeds.New(topicBytes, ownerAddr)).At(context.Background(), time.Now().Unix(), 0)
+	if err != nil {
+		return swarm.ZeroAddress, "", "", err
+	}
+	if ch == nil {
+		return swarm.ZeroAddress, "", "", fmt.Errorf("%w: no update found", blockstore.ErrNotFound)
+	}
+	s, err := soc.FromChunk(ch)
+	if err != nil {
+		return swarm.ZeroAddress, "", "", err
+	}
+	// span, timestamp and a plain or encrypted reference
+	update := s.WrappedChunk().Data()
+	if len(update) != 48 && len(update) != 80 {
+		return swarm.ZeroAddress, "", "", errInvalidFeedUpdate
+	}
+	curBytes, err := cur.MarshalBinary()
+	if err != nil {
+		return swarm.ZeroAddress, "", "", err
+	}
+	nextBytes, err := next.MarshalBinary()
+	if err != nil {
+		return swarm.ZeroAddress, "", "", err
+	}
+	return swarm.NewAddress(update[swarm.SpanSize+8:]), hex.EncodeToString(curBytes), hex.EncodeToString(nextBytes), nil
+}
+
+func parseFeed(owner, topic string) (common.Address, []byte, error) {
+	if !common.IsHexAddress(owner) {
+		return common.Address{}, nil, fmt.Errorf("invalid feed owner %q", owner)
+	}
+	topicBytes, err := hex.DecodeString(topic)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("invalid feed topic %q: %w", topic, err)
+	}
+	return common.HexToAddress(owner), topicBytes, nil
+}
+
+// putter stores chunks and counts them in the tag of u
+func (c *Client) putter(u uploadSettings) storage.Putter {
+	return storage.PutterFunc(func(ctx context.Context, ch swarm.Chunk) error {
+		if err := c.store.Put(ctx, ch); err != nil {
+			return err
+		}
+		if u.tag == 0 {
+			return nil
+		}
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		t, ok := c.tags[u.tag]
+		if !ok {
+			return fmt.Errorf("%w: tag %d", blockstore.ErrNotFound, u.tag)
+		}
+		t.total++
+		t.synced++
+		return nil
+	})
+}
+
+// split stores data as a chunk tree with the pipeline of bee and returns its root reference
+func (c *Client) split(u uploadSettings, data io.Reader) (swarm.Address, error) {
+	ctx := context.Background()
+	return builder.FeedPipeline(ctx, builder.NewPipelineBuilder(ctx, c.putter(u), u.encrypt, u.level), data)
+}
+
+// join returns a reader of the data at ref and its length
+func (c *Client) join(ctx context.Context, ref swarm.Address) (io.ReadCloser, int64, error) {
+	j, size, err := joiner.New(ctx, c.store, nil, ref)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, 0, fmt.Errorf("%w: %s", blockstore.ErrNotFound, ref)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return io.NopCloser(j), size, nil
+}
+
+// loadSaver stores manifest nodes with the settings of u
+func (c *Client) loadSaver(u uploadSettings) file.LoadSaver {
+	putter := c.putter(u)
+	return loadsave.New(c.store, putter, func() pipeline.Interface {
+		return builder.NewPipelineBuilder(context.Background(), putter, u.encrypt, u.level)
+	})
+}
+
+func (c *Client) pin(ref swarm.Address, pin bool) {
+	if !pin {
+		return
+	}
+	c.mtx.Lock()
+	c.pins[ref.ByteString()] = struct{}{}
+	c.mtx.Unlock()
+}
+
+func statusCode(err error) int {
+	if errors.Is(err, blockstore.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
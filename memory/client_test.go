@@ -0,0 +1,192 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/bee/mock"
+	"github.com/asabya/swarm-blockstore/blockstoretest"
+	"github.com/asabya/swarm-blockstore/memory"
+	"github.com/asabya/swarm-blockstore/tar"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestConformance(t *testing.T) {
+	blockstoretest.TestClient(t, func(*testing.T) blockstore.Client {
+		return memory.NewClient()
+	})
+}
+
+func TestReferencesMatchBee(t *testing.T) {
+	beeClient := mock.NewTestClient(t, mock.TestServerOptions{})
+	client := memory.NewClient()
+
+	for _, tc := range []struct {
+		name string
+		size int
+		opts []blockstore.UploadOption
+	}{
+		{name: "empty"},
+		{name: "chunk", size: swarm.ChunkSize},
+		{name: "tree", size: 130 * swarm.ChunkSize},
+		{name: "redundancy", size: 3 * swarm.ChunkSize, opts: []blockstore.UploadOption{blockstore.WithRedundancyLevel(redundancy.MEDIUM)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte{7}, tc.size)
+			expected, err := beeClient.UploadBlob(bytes.NewReader(data), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := client.UploadBlob(bytes.NewReader(data), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ref.Equal(expected) {
+				t.Fatalf("expected %s, got %s", expected, ref)
+			}
+			r, _, err := client.DownloadBlob(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("downloaded data differs")
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts []blockstore.UploadOption
+	}{
+		{name: "file"},
+		{name: "file error document", opts: []blockstore.UploadOption{blockstore.WithErrorDocument("file.txt")}},
+		{name: "file metadata", opts: []blockstore.UploadOption{blockstore.WithMetadata(map[string]string{"a": "b"})}},
+		{name: "file redundancy", opts: []blockstore.UploadOption{blockstore.WithRedundancyLevel(redundancy.MEDIUM)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte{7}, 3*swarm.ChunkSize)
+			expected, err := beeClient.UploadFileBzz(data, "file.txt", tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := client.UploadFileBzz(data, "file.txt", tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ref.Equal(expected) {
+				t.Fatalf("expected %s, got %s", expected, ref)
+			}
+		})
+	}
+
+	newStream := func() *tar.Stream {
+		s := tar.NewStream()
+		for _, item := range []struct{ path, data string }{
+			{"index.html", "home"},
+			{"404.html", "not found"},
+			{"about/index.html", "about"},
+		} {
+			if err := s.WriteItem(tar.CollectionItem{Path: item.path, Size: int64(len(item.data)), File: io.NopCloser(strings.NewReader(item.data))}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.End(); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	for _, tc := range []struct {
+		name string
+		opts []blockstore.UploadOption
+	}{
+		{name: "bzz"},
+		{name: "bzz documents", opts: []blockstore.UploadOption{blockstore.WithIndexDocument("index.html"), blockstore.WithErrorDocument("404.html")}},
+		{name: "bzz metadata", opts: []blockstore.UploadOption{blockstore.WithMetadata(map[string]string{"a": "b"})}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, err := beeClient.UploadBzz(newStream(), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := client.UploadBzz(newStream(), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ref.Equal(expected) {
+				t.Fatalf("expected %s, got %s", expected, ref)
+			}
+		})
+	}
+
+	owner := hex.EncodeToString(bytes.Repeat([]byte{1}, 20))
+	topic := hex.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	expected, err := beeClient.CreateFeedManifest(owner, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := client.CreateFeedManifest(owner, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(expected) {
+		t.Fatalf("expected feed manifest %s, got %s", expected, ref)
+	}
+}
+
+func TestUploadSOC(t *testing.T) {
+	client := memory.NewClient()
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(pk)
+	ch, err := cac.New([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := bytes.Repeat([]byte{3}, swarm.HashSize)
+	s := soc.New(id, ch)
+	if _, err = s.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	owner := hex.EncodeToString(s.OwnerAddress())
+
+	// a signature of another id does not recover the owner
+	_, err = client.UploadSOC(owner, hex.EncodeToString(bytes.Repeat([]byte{4}, swarm.HashSize)), hex.EncodeToString(s.Signature()), ch.Data())
+	if !errors.Is(err, blockstore.ErrInvalidSOC) {
+		t.Fatalf("expected ErrInvalidSOC, got %v", err)
+	}
+
+	addr, err := client.UploadSOC(owner, hex.EncodeToString(id), hex.EncodeToString(s.Signature()), ch.Data(), blockstore.WithPin(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch, err := client.DownloadChunk(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !soc.Valid(sch) {
+		t.Fatal("expected a valid single owner chunk")
+	}
+	if pinned, err := client.IsPinned(addr); err != nil || !pinned {
+		t.Fatalf("expected the chunk to be pinned, got %v, %v", pinned, err)
+	}
+	if _, err = client.DownloadChunk(context.Background(), ch.Address()); !errors.Is(err, blockstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
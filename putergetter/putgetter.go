@@ -8,33 +8,32 @@ import (
 )
 
 type PutGetter struct {
-	tag             uint32
-	api             blockstore.Client
-	batch           string
-	pin             bool
-	redundancyLevel string
+	store blockstore.ChunkStore
+	opts  []blockstore.UploadOption
 }
 
-func NewPutGetter(api blockstore.Client, batch, redundancyLevel string, pin bool) (*PutGetter, error) {
-	tag, err := api.CreateTag(swarm.ZeroAddress)
-	if err != nil {
-		return nil, err
+// NewPutGetter returns a PutGetter that uploads chunks to store with opts. If store is a
+// blockstore.Tagger the uploads are counted in a new tag.
+func NewPutGetter(store blockstore.ChunkStore, opts ...blockstore.UploadOption) (*PutGetter, error) {
+	if tagger, ok := store.(blockstore.Tagger); ok {
+		tag, err := tagger.CreateTag(swarm.ZeroAddress)
+		if err != nil {
+			return nil, err
+		}
+		opts = append([]blockstore.UploadOption{blockstore.WithTag(tag)}, opts...)
 	}
 	return &PutGetter{
-		tag:             tag,
-		api:             api,
-		batch:           batch,
-		pin:             pin,
-		redundancyLevel: redundancyLevel,
+		store: store,
+		opts:  opts,
 	}, nil
 }
 
 func (p *PutGetter) Get(ctx context.Context, address swarm.Address) (ch swarm.Chunk, err error) {
-	return p.api.DownloadChunk(ctx, address)
+	return p.store.DownloadChunk(ctx, address)
 }
 
 func (p *PutGetter) Put(_ context.Context, ch swarm.Chunk) error {
-	_, err := p.api.UploadChunk(p.tag, ch, p.batch, p.redundancyLevel, p.pin)
+	_, err := p.store.UploadChunk(ch, p.opts...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,78 @@
+package putergetter_test
+
+import (
+	"context"
+	"testing"
+
+	blockstore "github.com/asabya/swarm-blockstore"
+	"github.com/asabya/swarm-blockstore/putergetter"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// chunkStore is a blockstore.ChunkStore without any other capability
+type chunkStore struct {
+	chunks map[string]swarm.Chunk
+	tags   []uint32
+}
+
+func (s *chunkStore) UploadChunk(ch swarm.Chunk, opts ...blockstore.UploadOption) (swarm.Address, error) {
+	s.chunks[ch.Address().ByteString()] = ch
+	s.tags = append(s.tags, blockstore.NewUploadOptions(opts...).Tag)
+	return ch.Address(), nil
+}
+
+func (s *chunkStore) DownloadChunk(_ context.Context, address swarm.Address, _ ...blockstore.DownloadOption) (swarm.Chunk, error) {
+	ch, ok := s.chunks[address.ByteString()]
+	if !ok {
+		return nil, blockstore.ErrNotFound
+	}
+	return ch, nil
+}
+
+// taggingStore is a chunkStore that is also a blockstore.Tagger
+type taggingStore struct {
+	*chunkStore
+}
+
+func (taggingStore) CreateTag(swarm.Address) (uint32, error) { return 7, nil }
+
+func (taggingStore) GetTag(uint32) (int64, int64, int64, error) { return 0, 0, 0, nil }
+
+func TestPutGetter(t *testing.T) {
+	ch, err := cac.New([]byte("chunk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name  string
+		store func(*chunkStore) blockstore.ChunkStore
+		tag   uint32
+	}{
+		{name: "chunk store", store: func(s *chunkStore) blockstore.ChunkStore { return s }},
+		{name: "tagger", store: func(s *chunkStore) blockstore.ChunkStore { return taggingStore{s} }, tag: 7},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &chunkStore{chunks: make(map[string]swarm.Chunk)}
+			pg, err := putergetter.NewPutGetter(tc.store(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = pg.Put(ctx, ch); err != nil {
+				t.Fatal(err)
+			}
+			got, err := pg.Get(ctx, ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(ch) {
+				t.Fatal("unexpected chunk")
+			}
+			if len(s.tags) != 1 || s.tags[0] != tc.tag {
+				t.Fatalf("expected upload with tag %d, got %v", tc.tag, s.tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package signer
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/crypto/clef"
+)
+
+// ClefSigner is a crypto.Signer backed by a clef style external signer. It owns its connection
+// to the signer, which Close closes.
+type ClefSigner struct {
+	crypto.Signer
+	client *rpc.Client
+}
+
+// NewClefSigner connects to a clef style external signer over JSON-RPC at endpoint, which
+// can be an ipc path or an http url. If address is nil the first account of the signer is used.
+func NewClefSigner(endpoint string, address *common.Address) (*ClefSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var version string
+	if err = client.Call(&version, "account_version"); err != nil {
+		client.Close()
+		return nil, err
+	}
+	s, err := clef.NewSigner(&clefAccounts{client: client, endpoint: endpoint}, client, crypto.Recover, address)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &ClefSigner{Signer: s, client: client}, nil
+}
+
+// Close closes the connection to the signer
+func (s *ClefSigner) Close() {
+	s.client.Close()
+}
+
+// clefAccounts calls the account API of clef like external.ExternalSigner does, but over a
+// connection it shares with the signer
+type clefAccounts struct {
+	client   *rpc.Client
+	endpoint string
+}
+
+// Accounts lists the accounts of the signer, none if the signer can not be reached
+func (c *clefAccounts) Accounts() []accounts.Account {
+	var addresses []common.Address
+	if err := c.client.Call(&addresses, "account_list"); err != nil {
+		return nil
+	}
+	accts := make([]accounts.Account, 0, len(addresses))
+	for _, addr := range addresses {
+		accts = append(accts, accounts.Account{URL: accounts.URL{Scheme: "extapi", Path: c.endpoint}, Address: addr})
+	}
+	return accts
+}
+
+// SignData signs data of mimeType with account
+func (c *clefAccounts) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	signAddress := common.NewMixedcaseAddress(account.Address)
+	if err := c.client.Call(&sig, "account_signData", mimeType, &signAddress, hexutil.Encode(data)); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SignTx signs a legacy, access list or dynamic fee transaction with account
+func (c *clefAccounts) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		t := common.NewMixedcaseAddress(*tx.To())
+		to = &t
+	}
+	args := &apitypes.SendTxArgs{
+		Input: &data,
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Value: hexutil.Big(*tx.Value()),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		To:    to,
+		From:  common.NewMixedcaseAddress(account.Address),
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("%w: transaction type %d", ErrUnsupported, tx.Type())
+	}
+	if chainID != nil && chainID.Sign() != 0 {
+		args.ChainID = (*hexutil.Big)(chainID)
+	}
+	if tx.Type() != types.LegacyTxType {
+		if tx.ChainId().Sign() != 0 {
+			args.ChainID = (*hexutil.Big)(tx.ChainId())
+		}
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	var res struct {
+		Tx *types.Transaction `json:"tx"`
+	}
+	if err := c.client.Call(&res, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	return res.Tx, nil
+}
@@ -0,0 +1,168 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+)
+
+// The HTTP signer protocol has two endpoints:
+//
+//	GET  /address  responds with {"address": "0x..."}
+//	POST /sign     takes {"data": "0x..."} and responds with {"signature": "0x..."}
+//
+// Signatures are 65 bytes over the ethereum prefixed (eip191) data, with v at the end,
+// exactly what crypto.Signer.Sign returns.
+const (
+	httpAddressPath = "/address"
+	httpSignPath    = "/sign"
+	requestTimeout  = 30 * time.Second
+)
+
+type addressResponse struct {
+	Address common.Address `json:"address"`
+}
+
+type signRequest struct {
+	Data hexutil.Bytes `json:"data"`
+}
+
+type signResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+type httpSigner struct {
+	signOnly
+	url     string
+	client  *http.Client
+	address common.Address
+	pubKey  *ecdsa.PublicKey
+}
+
+// NewHTTPSigner connects to a signer speaking the HTTP signer protocol at url
+func NewHTTPSigner(url string) (crypto.Signer, error) {
+	s := &httpSigner{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: requestTimeout},
+	}
+
+	var addrResp addressResponse
+	if err := s.call(http.MethodGet, httpAddressPath, nil, &addrResp); err != nil {
+		return nil, err
+	}
+	s.address = addrResp.Address
+
+	pubKey, err := recoverPublicKey(s.Sign, s.address)
+	if err != nil {
+		return nil, err
+	}
+	s.pubKey = pubKey
+	return s, nil
+}
+
+// Sign signs data with ethereum prefix by the remote signer
+func (s *httpSigner) Sign(data []byte) ([]byte, error) {
+	var resp signResponse
+	if err := s.call(http.MethodPost, httpSignPath, &signRequest{Data: data}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Signature) != 65 {
+		return nil, crypto.ErrInvalidLength
+	}
+	return resp.Signature, nil
+}
+
+// PublicKey returns the public key recovered when connecting
+func (s *httpSigner) PublicKey() (*ecdsa.PublicKey, error) {
+	return s.pubKey, nil
+}
+
+// EthereumAddress returns the address reported by the remote signer
+func (s *httpSigner) EthereumAddress() (common.Address, error) {
+	return s.address, nil
+}
+
+func (s *httpSigner) call(method, path string, in, out interface{}) error {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, s.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	respData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("signer responded with %d: %s", response.StatusCode, strings.TrimSpace(string(respData)))
+	}
+	return json.Unmarshal(respData, out)
+}
+
+// NewHTTPHandler serves the HTTP signer protocol for signer, for example as a
+// local stand-in for a remote signing service
+func NewHTTPHandler(signer crypto.Signer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(httpAddressPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		address, err := signer.EthereumAddress()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJson(w, &addressResponse{Address: address})
+	})
+	mux.HandleFunc(httpSignPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req signRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid sign request", http.StatusBadRequest)
+			return
+		}
+		if len(req.Data) == 0 {
+			http.Error(w, "no data to sign", http.StatusBadRequest)
+			return
+		}
+		sig, err := signer.Sign(req.Data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJson(w, &signResponse{Signature: sig})
+	})
+	return mux
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,69 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/crypto/eip712"
+)
+
+var (
+	// ErrUnsupported is returned by signers for operations the backend does not offer
+	ErrUnsupported = errors.New("operation not supported by signer")
+	// ErrAddressMismatch is returned when a remote signer signs with a different key than it claims
+	ErrAddressMismatch = errors.New("signer address does not match recovered address")
+
+	recoveryMessage = []byte("public key recovery message")
+)
+
+// NewKeystoreSigner loads a private key from a go-ethereum encrypted keystore file
+func NewKeystoreSigner(path, password string) (crypto.Signer, error) {
+	keyJson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJson, password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore: %w", err)
+	}
+	return crypto.NewDefaultSigner(key.PrivateKey), nil
+}
+
+// recoverPublicKey signs the recovery message with sign and checks the key belongs to address
+func recoverPublicKey(sign func([]byte) ([]byte, error), address common.Address) (*ecdsa.PublicKey, error) {
+	sig, err := sign(recoveryMessage)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := crypto.Recover(sig, recoveryMessage)
+	if err != nil {
+		return nil, err
+	}
+	recovered, err := crypto.NewEthereumAddress(*pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if common.BytesToAddress(recovered) != address {
+		return nil, ErrAddressMismatch
+	}
+	return pubKey, nil
+}
+
+// signOnly provides the crypto.Signer methods that remote signers without
+// transaction support do not implement
+type signOnly struct{}
+
+func (signOnly) SignTx(*types.Transaction, *big.Int) (*types.Transaction, error) {
+	return nil, ErrUnsupported
+}
+
+func (signOnly) SignTypedData(*eip712.TypedData) ([]byte, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,162 @@
+package signer_test
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asabya/swarm-blockstore/signer"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/google/uuid"
+)
+
+// signsSOC checks that s produces valid single owner chunks
+func signsSOC(t *testing.T, s crypto.Signer) {
+	t.Helper()
+	ch, err := cac.New([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch, err := soc.New(make([]byte, 32), ch).Sign(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !soc.Valid(sch) {
+		t.Fatal("invalid soc")
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyJson, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    common.BytesToAddress(mustAddress(t, crypto.NewDefaultSigner(pk))),
+		PrivateKey: pk,
+	}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err = os.WriteFile(path, keyJson, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = signer.NewKeystoreSigner(path, "wrong"); err == nil {
+		t.Fatal("expected error for wrong password")
+	}
+	s, err := signer.NewKeystoreSigner(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signsSOC(t, s)
+}
+
+func TestHTTPSigner(t *testing.T) {
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := crypto.NewDefaultSigner(pk)
+	srv := httptest.NewServer(signer.NewHTTPHandler(local))
+	defer srv.Close()
+
+	s, err := signer.NewHTTPSigner(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(mustAddress(t, s)) != string(mustAddress(t, local)) {
+		t.Fatal("address mismatch")
+	}
+	signsSOC(t, s)
+	if _, err = s.SignTypedData(nil); !errors.Is(err, signer.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+// clefAPI is a minimal stand-in for the account namespace of clef
+type clefAPI struct {
+	signer crypto.Signer
+}
+
+func (c *clefAPI) Version() string {
+	return "6.0.0"
+}
+
+func (c *clefAPI) List() ([]common.Address, error) {
+	addr, err := c.signer.EthereumAddress()
+	if err != nil {
+		return nil, err
+	}
+	return []common.Address{addr}, nil
+}
+
+func (c *clefAPI) SignData(mimeType string, _ common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if mimeType != accounts.MimetypeTextPlain {
+		return nil, errors.New("unsupported mime type")
+	}
+	return c.signer.Sign(data)
+}
+
+func TestClefSigner(t *testing.T) {
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := crypto.NewDefaultSigner(pk)
+	server := rpc.NewServer()
+	if err = server.RegisterName("account", &clefAPI{signer: local}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	defer server.Stop()
+
+	s, err := signer.NewClefSigner(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(mustAddress(t, s)) != string(mustAddress(t, local)) {
+		t.Fatal("address mismatch")
+	}
+	signsSOC(t, s)
+	s.Close()
+
+	// the signer owns its connection, which is persistent over ipc
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "clef.ipc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.ServeListener(ln) }()
+	defer ln.Close()
+	s, err = signer.NewClefSigner(ln.Addr().String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signsSOC(t, s)
+	s.Close()
+	if _, err = s.Sign([]byte("closed")); err == nil {
+		t.Fatal("expected signing to fail after Close")
+	}
+}
+
+func mustAddress(t *testing.T, s crypto.Signer) []byte {
+	t.Helper()
+	addr, err := s.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr.Bytes()
+}
@@ -0,0 +1,81 @@
+package blockstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var (
+	// ErrInvalidSOC is returned when a single owner chunk or its signature is invalid
+	ErrInvalidSOC = errors.New("invalid single owner chunk")
+	// ErrSOCOwnerMismatch is returned when a single owner chunk is signed by another owner than expected
+	ErrSOCOwnerMismatch = errors.New("single owner chunk owner mismatch")
+
+	errInvalidSOCID = errors.New("single owner chunk id must be 32 bytes")
+)
+
+// WriteSOC wraps payload in a content addressed chunk, signs it as a single owner chunk
+// with id and uploads it with opts. It returns the address of the single owner chunk.
+func WriteSOC(ctx context.Context, c SOCStore, signer crypto.Signer, id, payload []byte, opts ...UploadOption) (swarm.Address, error) {
+	ch, err := cac.New(payload)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return WriteSOCChunk(ctx, c, signer, id, ch, opts...)
+}
+
+// WriteSOCChunk signs an existing content addressed chunk as a single owner chunk with id and uploads it
+func WriteSOCChunk(ctx context.Context, c SOCStore, signer crypto.Signer, id []byte, ch swarm.Chunk, opts ...UploadOption) (swarm.Address, error) {
+	if len(id) != swarm.HashSize {
+		return swarm.ZeroAddress, errInvalidSOCID
+	}
+	if err := ctx.Err(); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	s := soc.New(id, ch)
+	sch, err := s.Sign(signer)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	// remote signers may sign with another key than the public key they report
+	if !soc.Valid(sch) {
+		return swarm.ZeroAddress, ErrInvalidSOC
+	}
+	owner := hex.EncodeToString(s.OwnerAddress())
+	return c.UploadSOC(owner, hex.EncodeToString(id), hex.EncodeToString(s.Signature()), ch.Data(), opts...)
+}
+
+// ReadSOC downloads the single owner chunk of owner with id, checks its signature and
+// owner and returns the payload of the wrapped chunk
+func ReadSOC(ctx context.Context, c ChunkStore, owner common.Address, id []byte) ([]byte, error) {
+	if len(id) != swarm.HashSize {
+		return nil, errInvalidSOCID
+	}
+	addr, err := soc.CreateAddress(id, owner.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	ch, err := c.DownloadChunk(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	s, err := soc.FromChunk(ch)
+	if err != nil {
+		return nil, ErrInvalidSOC
+	}
+	if !bytes.Equal(s.OwnerAddress(), owner.Bytes()) {
+		return nil, ErrSOCOwnerMismatch
+	}
+	if !soc.Valid(ch) {
+		return nil, ErrInvalidSOC
+	}
+	return s.WrappedChunk().Data()[swarm.SpanSize:], nil
+}
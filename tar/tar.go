@@ -20,8 +20,9 @@ type Collection struct {
 
 // Stream is a tar stream writer
 type Stream struct {
-	buf *bytes.Buffer
-	w   *tar.Writer
+	buf   *bytes.Buffer
+	w     *tar.Writer
+	paths []string
 }
 
 // NewStream creates a new TarStream instance
@@ -41,7 +42,11 @@ func (ts *Stream) BeginFile(item CollectionItem) error {
 		Size:    item.Size,
 		ModTime: time.Now(),
 	}
-	return ts.w.WriteHeader(hdr)
+	if err := ts.w.WriteHeader(hdr); err != nil {
+		return err
+	}
+	ts.paths = append(ts.paths, item.Path)
+	return nil
 }
 
 // AppendFile appends data to the current file in the tar archive
@@ -65,6 +70,11 @@ func (ts *Stream) Output() *bytes.Buffer {
 	return ts.buf
 }
 
+// Paths returns the paths of the files in the tar archive
+func (ts *Stream) Paths() []string {
+	return ts.paths
+}
+
 // GetWriter returns the tar writer
 func (ts *Stream) GetWriter() *tar.Writer {
 	return ts.w
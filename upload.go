@@ -0,0 +1,125 @@
+package blockstore
+
+import (
+	"errors"
+
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var (
+	// ErrPushFailed is returned when the node fails to push the chunks of a direct upload to the network
+	ErrPushFailed = errors.New("push failed")
+	// ErrDirectPinOrTag is returned for a call that asks for a direct upload and also pins or tags it
+	ErrDirectPinOrTag = errors.New("direct uploads can not be pinned or tagged")
+)
+
+// UploadOption sets how an upload is stamped, stored and sent to the network
+type UploadOption func(*UploadOptions)
+
+// UploadOptions are the settings of an upload. A field set by an option takes precedence over the
+// default of the client, an unset field uses the default of the client and, if the client has none,
+// the default of the node. Every upload method applies them the same way.
+type UploadOptions struct {
+	// BatchID is the postage batch that stamps the chunks
+	BatchID string
+	// RedundancyLevel is the erasure coding level of blob and bzz uploads
+	RedundancyLevel *redundancy.Level
+	// Pin keeps the uploaded chunks on the node
+	Pin *bool
+	// Encrypt encrypts blob and bzz uploads, the reference includes the decryption key
+	Encrypt *bool
+	// Tag counts the upload progress
+	Tag uint32
+	// Deferred makes the node store the chunks and sync them in the background. Otherwise the node
+	// pushes them before it responds, and does not pin them or count them in a tag: a pin or tag set
+	// by the call makes an upload deferred when direct upload is the default of the client, and is
+	// an error when the call sets Deferred to false. Blob, bzz and feed manifest uploads follow
+	// Deferred. The node ignores it for chunk uploads, which it defers only with a tag, and for single
+	// owner chunk uploads, which it defers only when they are pinned.
+	Deferred *bool
+	// ACT protects the upload with the access control of the client. An upload with access
+	// control on a client without it starts a new history, its address is in the result.
+	ACT *bool
+	// Bzz configures the manifest of bzz uploads, other uploads ignore it
+	Bzz BzzOptions
+	// Result is set when the upload succeeds
+	Result *UploadResult
+}
+
+// UploadResult is how the node handled a successful upload
+type UploadResult struct {
+	// Pushed is whether the node pushed the chunks to the network before it responded, otherwise it
+	// stored them to sync them in the background
+	Pushed bool
+	// Tag counts the progress of the upload, it is zero if the upload has no tag
+	Tag uint32
+	// HistoryAddress is the access control history of the upload, it is zero if the upload has no
+	// access control. Downloads need it with the publisher to decrypt the reference.
+	HistoryAddress swarm.Address
+}
+
+// WithBatchID sets the postage batch that stamps the chunks
+func WithBatchID(batchID string) UploadOption {
+	return func(o *UploadOptions) {
+		o.BatchID = batchID
+	}
+}
+
+// WithRedundancyLevel sets the erasure coding level of blob and bzz uploads
+func WithRedundancyLevel(level redundancy.Level) UploadOption {
+	return func(o *UploadOptions) {
+		o.RedundancyLevel = &level
+	}
+}
+
+// WithPin sets whether the node keeps the uploaded chunks
+func WithPin(pin bool) UploadOption {
+	return func(o *UploadOptions) {
+		o.Pin = &pin
+	}
+}
+
+// WithEncrypt sets whether blob and bzz uploads are encrypted
+func WithEncrypt(encrypt bool) UploadOption {
+	return func(o *UploadOptions) {
+		o.Encrypt = &encrypt
+	}
+}
+
+// WithTag counts the upload progress in tag
+func WithTag(tag uint32) UploadOption {
+	return func(o *UploadOptions) {
+		o.Tag = tag
+	}
+}
+
+// WithDeferred sets whether the node syncs the upload in the background or pushes it before the call returns
+func WithDeferred(deferred bool) UploadOption {
+	return func(o *UploadOptions) {
+		o.Deferred = &deferred
+	}
+}
+
+// WithACT sets whether the upload is protected with the access control of the client
+func WithACT(act bool) UploadOption {
+	return func(o *UploadOptions) {
+		o.ACT = &act
+	}
+}
+
+// WithResult sets r to how the node handled the upload when it succeeds
+func WithResult(r *UploadResult) UploadOption {
+	return func(o *UploadOptions) {
+		o.Result = r
+	}
+}
+
+// NewUploadOptions returns the options set by opts
+func NewUploadOptions(opts ...UploadOption) *UploadOptions {
+	o := &UploadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}